@@ -0,0 +1,234 @@
+package main
+
+import (
+	"devread/crawler"
+	"devread/db"
+	"devread/dedupe"
+	"devread/model"
+	"devread/notify"
+	"devread/repository"
+	"devread/repository/repo_impl"
+	"devread/scheduler"
+	"devread/tagnorm"
+
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// crawl - runs the crawler as a worker, or once with --once and exits
+func crawl(ctx context.Context, log *zap.Logger, args []string) int {
+	flags := flag.NewFlagSet("crawl", flag.ContinueOnError)
+	once := flags.Bool("once", false, "crawl một lần rồi thoát")
+	full := flags.Bool("full", false, "với --once: crawl toàn bộ các trang thay vì dừng ở bài viết đã có")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if err := loadSources(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	sources := crawler.Sources()
+	if flags.NArg() > 0 {
+		sources = []crawler.Source{}
+		for _, name := range flags.Args() {
+			src, ok := crawler.Lookup(name)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Nguồn %q không tồn tại\n", name)
+				return 2
+			}
+			sources = append(sources, src)
+		}
+	}
+
+	client := connectRedis(log)
+	sql := connectPostgres(log)
+	defer sql.Close()
+
+	var mu sync.Mutex
+	runs := []model.CrawlRun{}
+	crawlScheduler, err := newCrawlScheduler(log, client, sql, sources, func(run model.CrawlRun) {
+		mu.Lock()
+		defer mu.Unlock()
+		runs = append(runs, run)
+	})
+	if err != nil {
+		log.Error("Lập lịch crawler thất bại ", zap.Error(err))
+		return 1
+	}
+
+	if !*once {
+		crawlScheduler.Start(ctx)
+		<-ctx.Done()
+		log.Info("Đang dừng crawler ")
+		crawlScheduler.Wait()
+		return 0
+	}
+
+	skipped := []skippedTask{}
+	var wg sync.WaitGroup
+	for _, src := range sources {
+		wg.Add(1)
+		task := src.Name()
+		if *full {
+			task = fullTask(src)
+		}
+		go func(name string) {
+			defer wg.Done()
+			if err := crawlScheduler.RunNow(ctx, name); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				skipped = append(skipped, skippedTask{name: name, reason: err})
+			}
+		}(task)
+	}
+	wg.Wait()
+
+	printSummary(os.Stdout, runs, skipped)
+	if len(runs) == 0 && len(skipped) > 0 {
+		return 1
+	}
+	for _, run := range runs {
+		if run.ErrorCount > 0 || run.PostsFound == 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+// loadSources - registers the sources declared in CRAWL_SOURCES_FILE (sources.json by default)
+func loadSources() error {
+	path := os.Getenv("CRAWL_SOURCES_FILE")
+	if path == "" {
+		path = crawler.DefaultSourcesFile
+	}
+	return crawler.LoadSources(path)
+}
+
+// newCrawlScheduler - schedules the given sources behind the redis crawl lock,
+// onRun (optional) receives every finished run
+func newCrawlScheduler(log *zap.Logger, client *db.RedisDB, sql *db.Sql, sources []crawler.Source, onRun func(model.CrawlRun)) (*scheduler.Scheduler, error) {
+	policy, err := crawler.PolicyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	policy.Logger = log
+	crawler.UsePolicy(policy)
+	crawler.UsePageCache(repo_impl.NewPageCacheRepo(client), log)
+
+	knownStreak := crawler.DefaultKnownStreak
+	if value := os.Getenv("CRAWL_KNOWN_STREAK"); value != "" {
+		knownStreak, err = strconv.Atoi(value)
+		if err != nil || knownStreak < 1 {
+			return nil, fmt.Errorf("CRAWL_KNOWN_STREAK không hợp lệ: %q", value)
+		}
+	}
+
+	postRepo := repo_impl.NewPostRepo(sql)
+	postCrawler := &crawler.Crawler{
+		PostRepo:      postRepo,
+		CrawlRunRepo:  repo_impl.NewCrawlRunRepo(sql),
+		TagNormalizer: tagnorm.NewNormalizer(repo_impl.NewTagRepo(sql), log),
+		Dedupe:        dedupe.NewDetector(postRepo, log),
+		Notifier:      notify.FromEnv(log),
+		Logger:        log,
+		KnownStreak:   knownStreak,
+	}
+
+	crawlScheduler := scheduler.NewScheduler("CRAWL", log)
+	crawlScheduler.UseLock(repo_impl.NewLockRepo(client), instanceID())
+	for _, src := range sources {
+		src := src
+		// both modes take the lock of the source, they would upsert the same rows
+		tasks := []scheduler.Task{
+			{Name: src.Name(), Spec: src.Schedule(), Run: crawlTask(postCrawler, src, crawler.Incremental, onRun), Lock: src.Name()},
+			{Name: fullTask(src), Spec: fullSpec, Run: crawlTask(postCrawler, src, crawler.Full, onRun), Lock: src.Name()},
+		}
+		for _, task := range tasks {
+			if err := crawlScheduler.Add(task); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	trendRepo := repo_impl.NewTrendRepo(sql)
+	err = crawlScheduler.Add(scheduler.Task{
+		Name: "trending",
+		Spec: scheduler.Spec{
+			Cron:         scheduler.Every(15 * time.Minute),
+			Jitter:       time.Minute,
+			RunOnStartup: true,
+		},
+		Run: func(ctx context.Context) {
+			refreshTrending(ctx, log, trendRepo)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return crawlScheduler, nil
+}
+
+// fullSpec - default schedule of the full backfill of every source, the other runs are incremental
+var fullSpec = scheduler.Spec{
+	Cron:   scheduler.Every(7 * 24 * time.Hour),
+	Jitter: 6 * time.Hour,
+}
+
+// fullTask - name of the backfill task of a source, its schedule is overridden by CRAWL_<SOURCE>_FULL_SCHEDULE
+func fullTask(src crawler.Source) string {
+	return src.Name() + "_full"
+}
+
+func crawlTask(postCrawler *crawler.Crawler, src crawler.Source, mode crawler.Mode, onRun func(model.CrawlRun)) func(context.Context) {
+	return func(ctx context.Context) {
+		run := postCrawler.Crawl(ctx, src, mode)
+		if onRun != nil {
+			onRun(run)
+		}
+	}
+}
+
+// refreshTrending - recomputes the trending score of every window
+func refreshTrending(ctx context.Context, log *zap.Logger, trendRepo repository.TrendRepo) {
+	for _, window := range model.TrendWindows {
+		if ctx.Err() != nil {
+			return
+		}
+		count, err := trendRepo.Refresh(ctx, window)
+		if err != nil {
+			log.Error("Tính điểm thịnh hành thất bại ", zap.String("window", window.Name), zap.Error(err))
+			continue
+		}
+		log.Info("Tính điểm thịnh hành xong ", zap.String("window", window.Name), zap.Int64("posts", count))
+	}
+}
+
+// skippedTask - a task of crawl --once that did not run
+type skippedTask struct {
+	name   string
+	reason error
+}
+
+func printSummary(w io.Writer, runs []model.CrawlRun, skipped []skippedTask) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tMODE\tPAGES\tFOUND\tINSERTED\tUPDATED\tERRORS\tLAST ERROR")
+	for _, run := range runs {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\n",
+			run.Source, run.Mode, run.PagesVisited, run.PostsFound,
+			run.PostsInserted, run.PostsUpdated, run.ErrorCount, run.LastError)
+	}
+	for _, task := range skipped {
+		fmt.Fprintf(tw, "%s\t-\t-\t-\t-\t-\t-\tbỏ qua: %v\n", task.name, task.reason)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,148 @@
+package crawler
+
+import (
+	"devread/model"
+	"devread/repository"
+
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxCachedPage - larger pages are fetched without being cached
+const maxCachedPage = 2 << 20
+
+var (
+	cacheMu     sync.RWMutex
+	pageCache   repository.PageCacheRepo
+	cacheLogger = zap.NewNop()
+)
+
+// UsePageCache - keeps the pages with an ETag or Last-Modified so the next crawl asks
+// the blog whether they changed, nil turns the cache off
+func UsePageCache(cache repository.PageCacheRepo, logger *zap.Logger) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	pageCache = cache
+	cacheLogger = logger
+}
+
+func currentPageCache() (repository.PageCacheRepo, *zap.Logger) {
+	cacheMu.RLock()
+	defer cacheMu.RUnlock()
+	return pageCache, cacheLogger
+}
+
+// cacheTransport - conditional GET: sends the validators of the cached page and
+// answers a 304 with the cached body, so the sources parse it as usual
+type cacheTransport struct {
+	next http.RoundTripper
+}
+
+func (t cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cache, logger := currentPageCache()
+	if cache == nil || req.Method != http.MethodGet {
+		return t.next.RoundTrip(req)
+	}
+
+	pageURL := req.URL.String()
+	cached, found, err := cache.Get(pageURL)
+	if err != nil {
+		// redis down: a full download still works
+		logger.Error("Đọc cache trang thất bại ", zap.String("url", pageURL), zap.Error(err))
+		found = false
+	}
+	if found {
+		req = req.Clone(req.Context())
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if found && resp.StatusCode == http.StatusNotModified {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		logger.Debug("Trang không đổi ", zap.String("url", pageURL))
+
+		// the 304 may carry new validators, saving again also extends the TTL
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			cached.ETag = etag
+		}
+		if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+			cached.LastModified = lastModified
+		}
+		cached.FetchedAt = time.Now()
+		if err := cache.Save(cached); err != nil {
+			logger.Error("Lưu cache trang thất bại ", zap.String("url", pageURL), zap.Error(err))
+		}
+		return cachedResponse(req, resp, cached), nil
+	}
+
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if resp.StatusCode != http.StatusOK || (etag == "" && lastModified == "") {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxCachedPage+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxCachedPage {
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	err = cache.Save(model.CachedPage{
+		URL:          pageURL,
+		ETag:         etag,
+		LastModified: lastModified,
+		ContentType:  resp.Header.Get("Content-Type"),
+		Body:         body,
+		FetchedAt:    time.Now(),
+	})
+	if err != nil {
+		logger.Error("Lưu cache trang thất bại ", zap.String("url", pageURL), zap.Error(err))
+	}
+	return resp, nil
+}
+
+// cachedResponse - 200 with the cached body and the headers of the 304
+func cachedResponse(req *http.Request, notModified *http.Response, cached model.CachedPage) *http.Response {
+	header := notModified.Header.Clone()
+	header.Set("Content-Type", cached.ContentType)
+	header.Set("Content-Length", strconv.Itoa(len(cached.Body)))
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       req,
+	}
+}
+
+// readCloser - reads from the buffered start then the rest of the body, closing the body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,57 @@
+package crawler
+
+import (
+	"devread/helper"
+	"devread/repository/repo_fake"
+
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestCacheTransportRevalidates(t *testing.T) {
+	full, notModified := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html>bài viết</html>"))
+	}))
+	defer server.Close()
+	helper.Transport = http.DefaultTransport
+	UsePolicy(Policy{UserAgent: "DevReadBot/test"})
+	UsePageCache(repo_fake.NewPageCacheRepo(), zap.NewNop())
+	defer UsePageCache(nil, zap.NewNop())
+
+	client := newFetcher(context.Background()).Client
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL + "/posts")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(body) != "<html>bài viết</html>" {
+			t.Fatalf("request %d: %d %q", i, resp.StatusCode, body)
+		}
+		if resp.Header.Get("Content-Type") != "text/html; charset=utf-8" {
+			t.Errorf("request %d: Content-Type = %q", i, resp.Header.Get("Content-Type"))
+		}
+	}
+	if full != 1 || notModified != 1 {
+		t.Fatalf("server sent %d full pages and %d 304, want 1 and 1", full, notModified)
+	}
+}
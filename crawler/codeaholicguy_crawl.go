@@ -1,11 +1,8 @@
 package crawler
 
 import (
-	"devread/custom_error"
-	"devread/handle_log"
-	"devread/helper"
 	"devread/model"
-	"devread/repository"
+	"devread/scheduler"
 
 	"context"
 	"fmt"
@@ -13,89 +10,78 @@ import (
 	"time"
 
 	"github.com/gocolly/colly/v2"
-	"go.uber.org/zap"
 )
 
-func CodeaholicguyPost(postRepo repository.PostRepo) {
-	log, _ := handle_log.WriteLog()
-
-	c := colly.NewCollector()
-	c.SetRequestTimeout(30 * time.Second)
-
-	posts := []model.Post{}
-	var codeaholicguyPost model.Post
-
-	c.OnHTML("span[class=cat-links]", func(e *colly.HTMLElement) {
-		if codeaholicguyPost.Name == "" || codeaholicguyPost.Link == "" {
-			return
-		}
-		codeaholicguyPost.Tag = strings.ToLower(strings.Replace(e.ChildText("span.cat-links > a:last-child"), "Chuyện coding", "", -1))
-		posts = append(posts, codeaholicguyPost)
-	})
+func init() {
+	Register(&codeaholicguySource{})
+}
 
-	c.OnHTML("header[class=entry-header]", func(e *colly.HTMLElement) {
-		codeaholicguyPost.Name = e.ChildText("h1.entry-title > a")
-		codeaholicguyPost.Link = e.ChildAttr("h1.entry-title > a", "href")
-		c.Visit(e.Request.AbsoluteURL(codeaholicguyPost.Link))
-		if codeaholicguyPost.Name == "" || codeaholicguyPost.Link == "" {
-			return
-		}
-		posts = append(posts, codeaholicguyPost)
-	})
+type codeaholicguySource struct{}
 
-	c.OnScraped(func(r *colly.Response) {
-		queue := helper.NewJobQueue(2)
-		queue.Start()
-		defer queue.Stop()
+func (s *codeaholicguySource) Name() string {
+	return "codeaholicguy"
+}
 
-		for _, post := range posts {
-			queue.Submit(&CodeaholicguyProcess{
-				post:     post,
-				postRepo: postRepo,
-			})
-		}
-	})
+func (s *codeaholicguySource) Schedule() scheduler.Spec {
+	return scheduler.Spec{
+		Cron:         scheduler.Every(96 * time.Hour),
+		Jitter:       time.Hour,
+		RunOnStartup: true,
+	}
+}
 
-	c.OnError(func(r *colly.Response, err error) {
-		log.Error("Lỗi: ", zap.String("Truy cập ", r.Request.URL.String()), zap.Error(err))
-	})
+func (s *codeaholicguySource) StartURLs() []string {
+	listURL := []string{}
 	for i := 1; i < 7; i++ {
 		fullURL := fmt.Sprintf("https://codeaholicguy.com/category/chuyen-coding/page/%d", i)
-		log.Sugar().Info("Truy cập: ", fullURL)
-		c.Visit(fullURL)
+		listURL = append(listURL, fullURL)
 	}
+	return listURL
 }
 
-type CodeaholicguyProcess struct {
-	post     model.Post
-	postRepo repository.PostRepo
-	logger   *zap.Logger
-}
+func (s *codeaholicguySource) Parse(ctx context.Context, pageURL string) ([]model.Post, error) {
+	c := newCollector(ctx)
+	article := c.Clone()
 
-func (process *CodeaholicguyProcess) Process() {
-	if process.logger == nil {
-		l, _ := handle_log.WriteLog()
-		process.logger = l
-	}
+	posts := []model.Post{}
 
-	// select post by link
-	cacheRepo, err := process.postRepo.SelectByLink(context.Background(), process.post.Link)
-	if err == custom_error.PostNotFound {
-		// insert post to database
-		process.logger.Sugar().Info("Thêm bài viết: ", process.post.Name)
-		_, err = process.postRepo.Save(context.Background(), process.post)
-		if err != nil {
-			process.logger.Error("Thêm bài viết thất bại ", zap.String("bài viết: ", process.post.Name), zap.Error(err))
+	// article page: tag and metadata
+	article.OnHTML("html", func(e *colly.HTMLElement) {
+		codeaholicguyPost := model.Post{
+			Name:   e.Request.Ctx.Get("name"),
+			Link:   e.Request.Ctx.Get("link"),
+			Tag:    strings.ToLower(strings.Replace(e.ChildText("span.cat-links > a:last-child"), "Chuyện coding", "", -1)),
+			Tags:   tagList(e.ChildTexts("span.cat-links > a"), "Chuyện coding"),
+			Author: strings.TrimSpace(e.ChildText("span.author a")),
 		}
-		return
-	}
+		codeaholicguyPost.PublishedAt = parseTime(e.ChildAttr("time.entry-date", "datetime"), time.RFC3339)
+		articleMeta(e, &codeaholicguyPost)
+		posts = append(posts, codeaholicguyPost)
+	})
+
+	// keep the post found on the listing even if its article page fails
+	article.OnError(func(r *colly.Response, err error) {
+		posts = append(posts, model.Post{
+			Name: r.Ctx.Get("name"),
+			Link: r.Ctx.Get("link"),
+		})
+	})
 
-	// update post
-	if process.post.Name != cacheRepo.Name {
-		process.logger.Sugar().Info("Cập nhật bài viết: ", process.post.Name)
-		_, err = process.postRepo.Update(context.Background(), process.post)
-		if err != nil {
-			process.logger.Error("Cập nhật bài viết thất bại ", zap.String("bài viết: ", process.post.Name), zap.Error(err))
+	// listing page: name and link of each post
+	c.OnHTML("header[class=entry-header]", func(e *colly.HTMLElement) {
+		name := e.ChildText("h1.entry-title > a")
+		link := e.ChildAttr("h1.entry-title > a", "href")
+		if name == "" || link == "" {
+			return
 		}
+		ctx := colly.NewContext()
+		ctx.Put("name", name)
+		ctx.Put("link", link)
+		article.Request("GET", e.Request.AbsoluteURL(link), nil, ctx, nil)
+	})
+
+	if err := c.Visit(pageURL); err != nil {
+		return posts, err
 	}
+	return posts, nil
 }
@@ -0,0 +1,42 @@
+package crawler
+
+import (
+	"devread/helper"
+
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// newCollector - collector shared by the colly based sources, its requests are revalidated against
+// the page cache (see cache.go), follow the fetch policy (see policy.go), go through helper.Transport
+// and are aborted when ctx is cancelled
+func newCollector(ctx context.Context) *colly.Collector {
+	p := currentPolicy()
+	c := colly.NewCollector(colly.UserAgent(p.UserAgent))
+	c.SetRequestTimeout(30 * time.Second)
+	// robots.txt and the delay between requests are handled by politeTransport for every
+	// collector at once, the rule only caps the requests of this collector
+	c.Limit(&colly.LimitRule{DomainGlob: "*", Parallelism: p.Parallelism})
+	c.WithTransport(contextTransport{ctx: ctx, next: cacheTransport{next: politeTransport{}}})
+	return c
+}
+
+// newFetcher - fetcher of the goquery based sources, with the same cache and policy
+func newFetcher(ctx context.Context) *helper.Fetcher {
+	f := helper.NewFetcher()
+	f.Client = &http.Client{Transport: contextTransport{ctx: ctx, next: cacheTransport{next: politeTransport{}}}}
+	return f
+}
+
+// contextTransport - colly v2.1 requests carry no context, bind them to the crawl context
+type contextTransport struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+func (t contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.next.RoundTrip(req.WithContext(t.ctx))
+}
@@ -0,0 +1,167 @@
+package crawler
+
+import (
+	"devread/scheduler"
+
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultSourcesFile - sources declared without code, overridden by CRAWL_SOURCES_FILE (.json, .yaml or .yml)
+const DefaultSourcesFile = "sources.json"
+
+// SourceConfig - a source declared in the sources file
+type SourceConfig struct {
+	Name string `json:"name" yaml:"name"`
+	// Type - "feed" for an RSS 2.0 / Atom feed, "selector" for a list page read with CSS selectors
+	Type string `json:"type" yaml:"type"`
+	// URL - the feed, or the only start URL of a selector source
+	URL string `json:"url" yaml:"url"`
+	// StartURLs - listings of a selector source, "{page}" is replaced by the numbers of Pagination
+	StartURLs  []string    `json:"start_urls" yaml:"start_urls"`
+	Pagination *Pagination `json:"pagination" yaml:"pagination"`
+	Selectors  *Selectors  `json:"selectors" yaml:"selectors"`
+	// DateFormats - Go layouts of the date selector, RFC 3339 by default
+	DateFormats []string `json:"date_formats" yaml:"date_formats"`
+	// Schedule - cron expression or descriptor, "@every 6h" by default
+	Schedule     string `json:"schedule" yaml:"schedule"`
+	Jitter       string `json:"jitter" yaml:"jitter"`
+	RunOnStartup bool   `json:"run_on_startup" yaml:"run_on_startup"`
+	// IgnoreTags - categories that are not tags, e.g. "Uncategorized"
+	IgnoreTags []string `json:"ignore_tags" yaml:"ignore_tags"`
+}
+
+// LoadSources - registers the sources of the file (JSON, or YAML for a .yaml/.yml file),
+// a missing file declares none
+func LoadSources(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	configs, err := decodeSources(path, data)
+	if err != nil {
+		return fmt.Errorf("%s không hợp lệ: %w", path, err)
+	}
+
+	sources := []Source{}
+	seen := map[string]bool{}
+	for _, config := range configs {
+		src, err := config.source()
+		if err != nil {
+			return fmt.Errorf("%s: nguồn %q: %w", path, config.Name, err)
+		}
+		if _, exist := Lookup(src.Name()); exist || seen[src.Name()] {
+			return fmt.Errorf("%s: nguồn %q đã được đăng ký", path, config.Name)
+		}
+		seen[src.Name()] = true
+		sources = append(sources, src)
+	}
+	// register only once the whole file is valid
+	for _, src := range sources {
+		Register(src)
+	}
+	return nil
+}
+
+// decodeSources - unknown fields are rejected, a misspelled selector would be silently ignored
+func decodeSources(path string, data []byte) ([]SourceConfig, error) {
+	configs := []SourceConfig{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err := yaml.UnmarshalStrict(data, &configs)
+		return configs, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&configs)
+	return configs, err
+}
+
+func (config SourceConfig) source() (Source, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("thiếu name")
+	}
+	spec, err := config.spec()
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.Type {
+	case "selector":
+		return newSelectorSource(config, spec)
+	case "feed":
+		if config.URL == "" {
+			return nil, fmt.Errorf("thiếu url")
+		}
+		return &feedSource{
+			name:     config.Name,
+			feedURL:  config.URL,
+			schedule: spec,
+			ignore:   config.IgnoreTags,
+		}, nil
+	}
+	return nil, fmt.Errorf("loại nguồn %q không được hỗ trợ", config.Type)
+}
+
+// listings - one listing per start URL, numbered by Pagination when it contains {page}
+func (config SourceConfig) listings() ([]Listing, error) {
+	urls := config.StartURLs
+	if config.URL != "" {
+		urls = append([]string{config.URL}, urls...)
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("thiếu url hoặc start_urls")
+	}
+
+	listings := []Listing{}
+	for _, u := range urls {
+		if !strings.Contains(u, pagePlaceholder) {
+			listings = append(listings, Listing{Pages: []string{u}})
+			continue
+		}
+		if config.Pagination == nil || config.Pagination.Last < 1 {
+			return nil, fmt.Errorf("%s cần pagination.last", u)
+		}
+		first := config.Pagination.First
+		if first == 0 {
+			first = 1
+		}
+		if first > config.Pagination.Last {
+			return nil, fmt.Errorf("pagination.first lớn hơn pagination.last")
+		}
+		format := strings.ReplaceAll(strings.ReplaceAll(u, "%", "%%"), pagePlaceholder, "%d")
+		listings = append(listings, Listing{Pages: numberedPages(format, first, config.Pagination.Last)})
+	}
+	return listings, nil
+}
+
+func (config SourceConfig) spec() (scheduler.Spec, error) {
+	spec := scheduler.Spec{
+		Cron:         scheduler.Every(6 * time.Hour),
+		Jitter:       30 * time.Minute,
+		RunOnStartup: config.RunOnStartup,
+	}
+	if config.Schedule != "" {
+		spec.Cron = config.Schedule
+	}
+	if config.Jitter != "" {
+		jitter, err := time.ParseDuration(config.Jitter)
+		if err != nil {
+			return spec, fmt.Errorf("jitter không hợp lệ: %w", err)
+		}
+		spec.Jitter = jitter
+	}
+	return spec, nil
+}
@@ -0,0 +1,318 @@
+package crawler
+
+import (
+	"devread/custom_error"
+	"devread/dedupe"
+	"devread/helper"
+	"devread/model"
+	"devread/notify"
+	"devread/repository"
+	"devread/tagnorm"
+
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type Crawler struct {
+	PostRepo      repository.PostRepo
+	CrawlRunRepo  repository.CrawlRunRepo
+	TagNormalizer *tagnorm.Normalizer
+	Dedupe        *dedupe.Detector
+	Notifier      notify.Notifier
+	Logger        *zap.Logger
+	// KnownStreak - see Incremental, DefaultKnownStreak when zero
+	KnownStreak int
+}
+
+// saveTimeout - time left to record a run after the crawl context is cancelled
+const saveTimeout = 5 * time.Second
+
+// Crawl - walks the listings of the source, saves the posts found and records the run.
+// A listing ends at its first page without posts, or in Incremental mode once KnownStreak
+// posts in a row are already stored. When ctx is cancelled the pages left are skipped
+// and the run is recorded as interrupted
+func (cr *Crawler) Crawl(ctx context.Context, src Source, mode Mode) model.CrawlRun {
+	stats := &runStats{
+		run: model.CrawlRun{
+			RunID:     uuid.New().String(),
+			Source:    src.Name(),
+			Mode:      string(mode),
+			StartedAt: time.Now(),
+		},
+	}
+
+	queue := helper.NewJobQueue(ctx, 2)
+	queue.Start()
+
+	for _, listing := range listings(ctx, src, mode) {
+		if !cr.crawlListing(ctx, src, mode, listing, queue, stats) {
+			break
+		}
+	}
+
+	// wait for every upsert before recording the run, a batch is written in one
+	// transaction so a cancelled upsert leaves nothing behind
+	queue.Stop()
+
+	stats.run.FinishedAt = time.Now()
+	if ctx.Err() != nil {
+		cr.Logger.Warn("Crawl bị dừng ", zap.String("source", src.Name()), zap.Error(ctx.Err()))
+		stats.fail(custom_error.CrawlInterrupted)
+		return cr.saveRun(stats.run)
+	}
+
+	history, err := cr.CrawlRunRepo.SelectAll(ctx, src.Name(), driftHistory)
+	if err != nil {
+		cr.Logger.Error("Đọc lịch sử crawl thất bại ", zap.String("source", src.Name()), zap.Error(err))
+	}
+	stats.run.Anomalies = detectDrift(stats.run, history)
+
+	run := cr.saveRun(stats.run)
+	cr.alert(ctx, run, history)
+	return run
+}
+
+// crawlListing - visits the pages of a listing in order, false when the crawl must stop
+func (cr *Crawler) crawlListing(ctx context.Context, src Source, mode Mode, listing Listing, queue *helper.JobQueue, stats *runStats) bool {
+	streak := 0
+	for _, pageURL := range listing.Pages {
+		if ctx.Err() != nil {
+			return false
+		}
+		cr.Logger.Sugar().Info("Truy cập: ", pageURL)
+		posts, err := src.Parse(ctx, pageURL)
+		if ctx.Err() != nil {
+			return false
+		}
+		if err != nil {
+			cr.Logger.Error("Lỗi: ", zap.String("source", src.Name()), zap.String("Truy cập ", pageURL), zap.Error(err))
+			stats.fail(err)
+			continue
+		}
+		stats.visit(posts)
+		if len(posts) == 0 {
+			return true
+		}
+		// counted above for drift detection, but a post without name or link can't be stored
+		if posts = complete(posts); len(posts) == 0 {
+			cr.Logger.Warn("Không có bài viết nào đủ tên và link ", zap.String("source", src.Name()), zap.String("url", pageURL))
+			continue
+		}
+
+		for i := range posts {
+			posts[i].Source = src.Name()
+			posts[i].Link = helper.CanonicalURL(posts[i].Link)
+			cr.normalizeTags(&posts[i])
+		}
+
+		// checked before the upsert, which makes every post of the page known
+		stop := false
+		if mode == Incremental && !listing.Unordered {
+			streak, stop = cr.knownStreak(ctx, posts, streak)
+		}
+
+		err = queue.Submit(&UpsertJob{
+			posts:    posts,
+			postRepo: cr.PostRepo,
+			dedupe:   cr.Dedupe,
+			logger:   cr.Logger,
+			stats:    stats,
+		})
+		if err != nil {
+			return false
+		}
+		if stop {
+			cr.Logger.Sugar().Info("Dừng danh sách tại ", pageURL, ": ", streak, " bài viết liên tiếp đã có")
+			return true
+		}
+	}
+	return true
+}
+
+// knownStreak - continues the streak of already stored posts of the previous pages over the page,
+// true once it reached the limit
+func (cr *Crawler) knownStreak(ctx context.Context, posts []model.Post, streak int) (int, bool) {
+	links := make([]string, 0, len(posts))
+	for _, post := range posts {
+		links = append(links, post.Link)
+	}
+	known, err := cr.PostRepo.SelectKnownLinks(ctx, links)
+	if err != nil {
+		// keep walking, the worst case is a full crawl
+		cr.Logger.Error("Kiểm tra bài viết đã có thất bại ", zap.Error(err))
+		return 0, false
+	}
+
+	for _, post := range posts {
+		if !known[post.Link] {
+			streak = 0
+			continue
+		}
+		streak++
+		if streak >= cr.streakLimit() {
+			return streak, true
+		}
+	}
+	return streak, false
+}
+
+func (cr *Crawler) streakLimit() int {
+	if cr.KnownStreak > 0 {
+		return cr.KnownStreak
+	}
+	return DefaultKnownStreak
+}
+
+// saveRun - records the run even when the crawl context is already cancelled
+func (cr *Crawler) saveRun(run model.CrawlRun) model.CrawlRun {
+	ctx, cancel := context.WithTimeout(context.Background(), saveTimeout)
+	defer cancel()
+
+	run, err := cr.CrawlRunRepo.Save(ctx, run)
+	if err != nil {
+		cr.Logger.Error("Lưu lịch sử crawl thất bại ", zap.String("source", run.Source), zap.Error(err))
+	}
+	cr.Logger.Info("Crawl xong ",
+		zap.String("source", run.Source),
+		zap.Int("pages", run.PagesVisited),
+		zap.Int("found", run.PostsFound),
+		zap.Int("inserted", run.PostsInserted),
+		zap.Int("updated", run.PostsUpdated),
+		zap.Int("errors", run.ErrorCount))
+	return run
+}
+
+// alert - notifies the anomalies of a run, only when the previous run was fine
+// so a broken source does not send the same alert on every run
+func (cr *Crawler) alert(ctx context.Context, run model.CrawlRun, history []model.CrawlRun) {
+	if len(run.Anomalies) == 0 {
+		return
+	}
+	if len(history) > 0 && len(history[0].Anomalies) > 0 {
+		cr.Logger.Warn("Crawler vẫn bất thường ", zap.String("source", run.Source), zap.Strings("anomalies", run.Anomalies))
+		return
+	}
+	if cr.Notifier == nil {
+		return
+	}
+
+	err := cr.Notifier.Notify(ctx, notify.Alert{
+		Source:    run.Source,
+		RunID:     run.RunID,
+		StartedAt: run.StartedAt,
+		Anomalies: run.Anomalies,
+	})
+	if err != nil {
+		cr.Logger.Error("Gửi cảnh báo crawler thất bại ", zap.String("source", run.Source), zap.Error(err))
+	}
+}
+
+// normalizeTags - canonical tags, the main tag being one of them
+func (cr *Crawler) normalizeTags(post *model.Post) {
+	post.Tags = cr.TagNormalizer.CanonicalList(post.Tags)
+	post.Tag = cr.TagNormalizer.Canonical(post.Tag)
+	if len(post.Tags) == 0 {
+		return
+	}
+	for _, tag := range post.Tags {
+		if tag == post.Tag {
+			return
+		}
+	}
+	post.Tag = post.Tags[0]
+}
+
+// runStats - counters of a crawl run shared by the upsert workers
+type runStats struct {
+	mu  sync.Mutex
+	run model.CrawlRun
+}
+
+// visit - counts a parsed page, fields are checked before normalization to see what the selectors returned
+func (s *runStats) visit(posts []model.Post) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.run.PagesVisited++
+	s.run.PostsFound += len(posts)
+	for _, post := range posts {
+		if strings.TrimSpace(post.Name) == "" {
+			s.run.EmptyNames++
+		}
+		if strings.TrimSpace(post.Link) == "" {
+			s.run.EmptyLinks++
+		}
+		if strings.TrimSpace(post.Tag) == "" && len(post.Tags) == 0 {
+			s.run.EmptyTags++
+		}
+	}
+}
+
+func (s *runStats) upserted(inserted, updated int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.run.PostsInserted += inserted
+	s.run.PostsUpdated += updated
+}
+
+func (s *runStats) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.run.ErrorCount++
+	s.run.LastError = err.Error()
+}
+
+// UpsertJob - saves the posts of a page in one batch
+type UpsertJob struct {
+	posts    []model.Post
+	postRepo repository.PostRepo
+	dedupe   *dedupe.Detector
+	logger   *zap.Logger
+	stats    *runStats
+}
+
+func (job *UpsertJob) Process(ctx context.Context) {
+	if len(job.posts) == 0 {
+		return
+	}
+
+	result, err := job.postRepo.UpsertMany(ctx, job.posts)
+	if err != nil {
+		job.logger.Error("Lưu bài viết thất bại ", zap.Int("bài viết", len(job.posts)), zap.Error(err))
+		job.stats.fail(err)
+		return
+	}
+	job.stats.upserted(len(result.Inserted), result.Updated)
+
+	for _, post := range result.Inserted {
+		job.logger.Sugar().Info("Thêm bài viết: ", post.Name)
+		job.groupDuplicate(ctx, post)
+	}
+}
+
+// groupDuplicate - puts a new post under the same article already crawled from another source
+func (job *UpsertJob) groupDuplicate(ctx context.Context, post model.Post) {
+	if job.dedupe == nil {
+		return
+	}
+	if _, err := job.dedupe.Group(ctx, post); err != nil {
+		job.logger.Error("Tìm bài viết trùng thất bại ", zap.String("bài viết: ", post.Name), zap.Error(err))
+		job.stats.fail(err)
+	}
+}
+
+// complete - the posts with a name and a link
+func complete(posts []model.Post) []model.Post {
+	kept := posts[:0]
+	for _, post := range posts {
+		if strings.TrimSpace(post.Name) == "" || strings.TrimSpace(post.Link) == "" {
+			continue
+		}
+		kept = append(kept, post)
+	}
+	return kept
+}
@@ -0,0 +1,165 @@
+package crawler
+
+import (
+	"devread/custom_error"
+	"devread/model"
+	"devread/repository/repo_fake"
+	"devread/scheduler"
+	"devread/tagnorm"
+
+	"context"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// onePage - a source limited to its first start URL, the one with fixtures
+type onePage struct {
+	Source
+}
+
+func (s onePage) StartURLs() []string {
+	return s.Source.StartURLs()[:1]
+}
+
+func TestCrawlUpsertsIntoRepo(t *testing.T) {
+	serveFixtures(t, "viblo")
+	src, _ := Lookup("viblo")
+
+	postRepo := repo_fake.NewPostRepo()
+	cr := &Crawler{
+		PostRepo:      postRepo,
+		CrawlRunRepo:  repo_fake.NewCrawlRunRepo(),
+		TagNormalizer: tagnorm.NewNormalizer(repo_fake.NewTagRepo(), zap.NewNop()),
+		Logger:        zap.NewNop(),
+	}
+
+	run := cr.Crawl(context.Background(), onePage{src}, Incremental)
+	if run.PagesVisited != 1 || run.PostsFound != 2 || run.PostsInserted != 2 || run.ErrorCount != 0 {
+		t.Fatalf("first crawl: %+v", run)
+	}
+
+	posts := postRepo.Posts()
+	if len(posts) != 2 {
+		t.Fatalf("stored %d posts, want 2", len(posts))
+	}
+	for _, post := range posts {
+		if post.Source != "viblo" {
+			t.Errorf("post %q has source %q", post.Name, post.Source)
+		}
+	}
+	// tracking parameters are dropped before saving
+	if link := posts[1].Link; link != "https://viblo.asia/p/docker-cho-nguoi-moi-bat-dau-Az45bD1Plxw" {
+		t.Errorf("link not canonical: %q", link)
+	}
+
+	run = cr.Crawl(context.Background(), onePage{src}, Incremental)
+	if run.PostsInserted != 0 || run.PostsUpdated != 0 {
+		t.Fatalf("second crawl of the same page changed posts: %+v", run)
+	}
+	if len(postRepo.Posts()) != 2 {
+		t.Fatalf("second crawl duplicated posts")
+	}
+}
+
+func TestCrawlCancelled(t *testing.T) {
+	serveFixtures(t, "viblo")
+	src, _ := Lookup("viblo")
+
+	postRepo := repo_fake.NewPostRepo()
+	runRepo := repo_fake.NewCrawlRunRepo()
+	cr := &Crawler{
+		PostRepo:      postRepo,
+		CrawlRunRepo:  runRepo,
+		TagNormalizer: tagnorm.NewNormalizer(repo_fake.NewTagRepo(), zap.NewNop()),
+		Logger:        zap.NewNop(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	run := cr.Crawl(ctx, src, Incremental)
+	if run.PagesVisited != 0 || run.LastError != custom_error.CrawlInterrupted.Error() {
+		t.Fatalf("cancelled crawl: %+v", run)
+	}
+	if len(postRepo.Posts()) != 0 {
+		t.Fatalf("cancelled crawl saved posts")
+	}
+	// the interrupted run is still recorded
+	if runs, _ := runRepo.SelectAll(context.Background(), "viblo", 10); len(runs) != 1 {
+		t.Fatalf("recorded %d runs, want 1", len(runs))
+	}
+}
+
+// pagedSource - two posts per page, no network
+type pagedSource struct {
+	pages int
+	// empty - the page numbers without posts
+	empty map[int]bool
+}
+
+func (s pagedSource) Name() string             { return "paged" }
+func (s pagedSource) Schedule() scheduler.Spec { return scheduler.Spec{} }
+
+func (s pagedSource) StartURLs() []string {
+	return numberedPages("https://example.com/page/%d", 1, s.pages)
+}
+
+func (s pagedSource) Parse(ctx context.Context, pageURL string) ([]model.Post, error) {
+	var page int
+	fmt.Sscanf(pageURL, "https://example.com/page/%d", &page)
+	if s.empty[page] {
+		return nil, nil
+	}
+	posts := []model.Post{}
+	for i := 1; i <= 2; i++ {
+		posts = append(posts, model.Post{
+			Name: fmt.Sprintf("Bài viết %d.%d", page, i),
+			Link: fmt.Sprintf("https://example.com/p/%d-%d", page, i),
+			Tag:  "go",
+		})
+	}
+	return posts, nil
+}
+
+func TestCrawlIncrementalStopsAtKnownPosts(t *testing.T) {
+	postRepo := repo_fake.NewPostRepo()
+	cr := &Crawler{
+		PostRepo:      postRepo,
+		CrawlRunRepo:  repo_fake.NewCrawlRunRepo(),
+		TagNormalizer: tagnorm.NewNormalizer(repo_fake.NewTagRepo(), zap.NewNop()),
+		Logger:        zap.NewNop(),
+		KnownStreak:   3,
+	}
+	src := pagedSource{pages: 5}
+
+	// pages 2 and 3 already crawled
+	existing, _ := src.Parse(context.Background(), "https://example.com/page/2")
+	more, _ := src.Parse(context.Background(), "https://example.com/page/3")
+	postRepo.UpsertMany(context.Background(), append(existing, more...))
+
+	run := cr.Crawl(context.Background(), src, Incremental)
+	// page 1 is new, the streak reaches 3 on the first post of page 3
+	if run.PagesVisited != 3 || run.PostsInserted != 2 {
+		t.Fatalf("incremental crawl: %+v", run)
+	}
+
+	run = cr.Crawl(context.Background(), src, Full)
+	if run.PagesVisited != 5 || run.PostsInserted != 4 {
+		t.Fatalf("full crawl: %+v", run)
+	}
+}
+
+func TestCrawlStopsAtEmptyPage(t *testing.T) {
+	cr := &Crawler{
+		PostRepo:      repo_fake.NewPostRepo(),
+		CrawlRunRepo:  repo_fake.NewCrawlRunRepo(),
+		TagNormalizer: tagnorm.NewNormalizer(repo_fake.NewTagRepo(), zap.NewNop()),
+		Logger:        zap.NewNop(),
+	}
+
+	run := cr.Crawl(context.Background(), pagedSource{pages: 5, empty: map[int]bool{3: true}}, Full)
+	if run.PagesVisited != 3 || run.PostsInserted != 4 {
+		t.Fatalf("crawl past the last page: %+v", run)
+	}
+}
@@ -0,0 +1,92 @@
+package crawler
+
+import (
+	"devread/model"
+
+	"fmt"
+)
+
+const (
+	// driftHistory - previous runs the current run is compared with
+	driftHistory = 10
+	// minHistory - runs needed before the yield of a source is trusted as a baseline
+	minHistory = 3
+	// minYieldRatio - posts per page below this share of the baseline is an anomaly
+	minYieldRatio = 0.5
+	// maxEmptyRise - rise of an empty-field ratio over the baseline that is an anomaly
+	maxEmptyRise = 0.2
+)
+
+// detectDrift - compares a run with the previous runs of its source (newest first)
+// and describes what looks like a broken selector, empty when nothing is wrong
+func detectDrift(run model.CrawlRun, history []model.CrawlRun) []string {
+	if run.PagesVisited == 0 {
+		if run.ErrorCount > 0 {
+			return []string{fmt.Sprintf("Không truy cập được trang nào (lỗi cuối: %s)", run.LastError)}
+		}
+		return nil
+	}
+	if run.PostsFound == 0 {
+		return []string{fmt.Sprintf("Không tìm thấy bài viết nào trên %d trang", run.PagesVisited)}
+	}
+
+	base := newBaseline(history)
+	anomalies := []string{}
+
+	perPage := float64(run.PostsFound) / float64(run.PagesVisited)
+	if base.runs >= minHistory && perPage < base.perPage*minYieldRatio {
+		anomalies = append(anomalies, fmt.Sprintf(
+			"Số bài viết mỗi trang giảm còn %.1f (trung bình %.1f)", perPage, base.perPage))
+	}
+
+	fields := []struct {
+		name  string
+		empty int
+		base  float64
+	}{
+		{"tên", run.EmptyNames, base.emptyNames},
+		{"link", run.EmptyLinks, base.emptyLinks},
+		{"tag", run.EmptyTags, base.emptyTags},
+	}
+	for _, field := range fields {
+		ratio := float64(field.empty) / float64(run.PostsFound)
+		if ratio > field.base+maxEmptyRise {
+			anomalies = append(anomalies, fmt.Sprintf(
+				"%.0f%% bài viết thiếu %s (trước đây %.0f%%)", ratio*100, field.name, field.base*100))
+		}
+	}
+	return anomalies
+}
+
+// baseline - average yield of the previous runs that found posts
+type baseline struct {
+	runs       int
+	perPage    float64
+	emptyNames float64
+	emptyLinks float64
+	emptyTags  float64
+}
+
+func newBaseline(history []model.CrawlRun) baseline {
+	var b baseline
+	var pages, found, emptyNames, emptyLinks, emptyTags int
+	for _, run := range history {
+		if run.PagesVisited == 0 || run.PostsFound == 0 {
+			continue
+		}
+		b.runs++
+		pages += run.PagesVisited
+		found += run.PostsFound
+		emptyNames += run.EmptyNames
+		emptyLinks += run.EmptyLinks
+		emptyTags += run.EmptyTags
+	}
+	if b.runs == 0 {
+		return b
+	}
+	b.perPage = float64(found) / float64(pages)
+	b.emptyNames = float64(emptyNames) / float64(found)
+	b.emptyLinks = float64(emptyLinks) / float64(found)
+	b.emptyTags = float64(emptyTags) / float64(found)
+	return b
+}
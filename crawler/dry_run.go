@@ -0,0 +1,30 @@
+package crawler
+
+import (
+	"devread/model"
+
+	"context"
+)
+
+// PageResult - posts parsed from one page by DryRun
+type PageResult struct {
+	URL   string
+	Posts []model.Post
+	Err   error
+}
+
+// DryRun - parses the first pages of every listing of src without storing anything,
+// to check the selectors of a source before it is scheduled
+func DryRun(ctx context.Context, src Source, pages int) []PageResult {
+	results := []PageResult{}
+	for _, listing := range listings(ctx, src, Incremental) {
+		for i, pageURL := range listing.Pages {
+			if i >= pages || ctx.Err() != nil {
+				break
+			}
+			posts, err := src.Parse(ctx, pageURL)
+			results = append(results, PageResult{URL: pageURL, Posts: posts, Err: err})
+		}
+	}
+	return results
+}
@@ -0,0 +1,221 @@
+package crawler
+
+import (
+	"devread/model"
+	"devread/scheduler"
+
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+)
+
+// feedSource - a blog read from its RSS 2.0 or Atom feed, declared in the sources file (see config.go)
+type feedSource struct {
+	name     string
+	feedURL  string
+	schedule scheduler.Spec
+	ignore   []string
+}
+
+func (s *feedSource) Name() string {
+	return s.name
+}
+
+func (s *feedSource) Schedule() scheduler.Spec {
+	return s.schedule
+}
+
+func (s *feedSource) StartURLs() []string {
+	return []string{s.feedURL}
+}
+
+func (s *feedSource) Parse(ctx context.Context, pageURL string) ([]model.Post, error) {
+	response, err := newFetcher(ctx).Get(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	posts, err := parseFeed(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("đọc feed %s thất bại: %w", pageURL, err)
+	}
+	for i := range posts {
+		posts[i].Tags = tagList(posts[i].Tags, s.ignore...)
+		if len(posts[i].Tags) > 0 {
+			posts[i].Tag = posts[i].Tags[0]
+		}
+	}
+	return posts, nil
+}
+
+type rssItem struct {
+	Title      string   `xml:"title"`
+	Link       string   `xml:"link"`
+	Categories []string `xml:"category"`
+	PubDate    string   `xml:"pubDate"`
+	Author     string   `xml:"author"`
+	// Creator - dc:creator, the author of WordPress feeds
+	Creator     string `xml:"creator"`
+	Description string `xml:"description"`
+	Enclosure   struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+}
+
+type rssFeed struct {
+	Items []rssItem `xml:"channel>item"`
+}
+
+type atomEntry struct {
+	Title string `xml:"title"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Author    struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Summary string `xml:"summary"`
+	Content string `xml:"content"`
+}
+
+type atomFeed struct {
+	Entries []atomEntry `xml:"entry"`
+}
+
+// rssDates - pubDate is RFC 822, with a few common deviations
+var rssDates = []string{
+	time.RFC1123Z, time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST",
+	time.RFC3339,
+}
+
+// parseFeed - posts of an RSS 2.0 or Atom document, in the order of the feed (newest first)
+func parseFeed(r io.Reader) ([]model.Post, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charset.NewReaderLabel
+	// feeds in the wild often use HTML entities such as &nbsp;
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	root, err := rootElement(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := []model.Post{}
+	switch root.Name.Local {
+	case "rss":
+		var feed rssFeed
+		if err := decoder.DecodeElement(&feed, &root); err != nil {
+			return nil, err
+		}
+		for _, item := range feed.Items {
+			posts = append(posts, rssPost(item))
+		}
+	case "feed":
+		var feed atomFeed
+		if err := decoder.DecodeElement(&feed, &root); err != nil {
+			return nil, err
+		}
+		for _, entry := range feed.Entries {
+			posts = append(posts, atomPost(entry))
+		}
+	default:
+		return nil, fmt.Errorf("định dạng feed <%s> không được hỗ trợ", root.Name.Local)
+	}
+	return posts, nil
+}
+
+func rootElement(decoder *xml.Decoder) (xml.StartElement, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
+func rssPost(item rssItem) model.Post {
+	post := model.Post{
+		Name:        strings.TrimSpace(item.Title),
+		Link:        strings.TrimSpace(item.Link),
+		Tags:        item.Categories,
+		Author:      strings.TrimSpace(item.Creator),
+		Excerpt:     excerpt(htmlText(item.Description)),
+		PublishedAt: parseTime(item.PubDate, rssDates...),
+	}
+	if post.Author == "" {
+		post.Author = rssAuthor(item.Author)
+	}
+	if strings.HasPrefix(item.Enclosure.Type, "image/") {
+		post.CoverImage = item.Enclosure.URL
+	}
+	return post
+}
+
+func atomPost(entry atomEntry) model.Post {
+	post := model.Post{
+		Name:        strings.TrimSpace(entry.Title),
+		Author:      strings.TrimSpace(entry.Author.Name),
+		PublishedAt: parseTime(entry.Published, time.RFC3339),
+	}
+	if post.PublishedAt == nil {
+		post.PublishedAt = parseTime(entry.Updated, time.RFC3339)
+	}
+	for _, link := range entry.Links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			post.Link = strings.TrimSpace(link.Href)
+			break
+		}
+	}
+	for _, category := range entry.Categories {
+		post.Tags = append(post.Tags, category.Term)
+	}
+	summary := entry.Summary
+	if summary == "" {
+		summary = entry.Content
+	}
+	post.Excerpt = excerpt(htmlText(summary))
+	return post
+}
+
+// rssEmailAuthor - "email (Name)", the form of the RSS <author> element
+var rssEmailAuthor = regexp.MustCompile(`^\S+@\S+\s+\((.+)\)$`)
+
+func rssAuthor(author string) string {
+	author = strings.TrimSpace(author)
+	if match := rssEmailAuthor.FindStringSubmatch(author); match != nil {
+		return match[1]
+	}
+	if strings.Contains(author, "@") {
+		return ""
+	}
+	return author
+}
+
+// htmlText - text of the HTML summary of a feed item
+func htmlText(html string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return html
+	}
+	return strings.ReplaceAll(doc.Text(), " ", " ")
+}
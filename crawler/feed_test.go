@@ -0,0 +1,97 @@
+package crawler
+
+import (
+	"devread/helper"
+
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFeedSource(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir(filepath.Join("testdata", "feed"))))
+	defer server.Close()
+	helper.Transport = http.DefaultTransport
+	UsePolicy(Policy{UserAgent: "DevReadBot/test"})
+
+	src, err := SourceConfig{Name: "blog", Type: "feed", URL: server.URL + "/rss.xml", IgnoreTags: []string{"Uncategorized"}}.source()
+	if err != nil {
+		t.Fatalf("source: %v", err)
+	}
+
+	tests := []struct {
+		file      string
+		link      string
+		published time.Time
+		tags      []string
+		author    string
+		excerpt   string
+		cover     string
+	}{
+		{"rss.xml", "https://blog.example.com/hoc-go-trong-10-phut/?utm_source=rss", time.Date(2021, 6, 7, 8, 30, 0, 0, time.UTC), []string{"chuyện coding", "go"},
+			"Nguyễn Văn A", "Go là ngôn ngữ đơn giản, nhanh và dễ học.", "https://blog.example.com/cover.png"},
+		{"atom.xml", "https://blog.example.com/hoc-go-trong-10-phut/", time.Date(2021, 6, 7, 1, 30, 0, 0, time.UTC), []string{"go", "backend"},
+			"Nguyễn Văn A", "Go là ngôn ngữ đơn giản.", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			posts, err := src.Parse(context.Background(), server.URL+"/"+tt.file)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(posts) != 2 {
+				t.Fatalf("got %d posts, want 2", len(posts))
+			}
+
+			post := posts[0]
+			if post.Name != "Học Go trong 10 phút" || post.Author != tt.author || post.CoverImage != tt.cover {
+				t.Errorf("post = %+v", post)
+			}
+			// canonicalized later by Crawl
+			if post.Link != tt.link {
+				t.Errorf("link = %q, want %q", post.Link, tt.link)
+			}
+			if post.PublishedAt == nil || !post.PublishedAt.Equal(tt.published) {
+				t.Errorf("published = %v, want %v", post.PublishedAt, tt.published)
+			}
+			if !reflect.DeepEqual([]string(post.Tags), tt.tags) || post.Tag != tt.tags[0] {
+				t.Errorf("tags = %q (%q), want %q", post.Tags, post.Tag, tt.tags)
+			}
+			if post.Excerpt != tt.excerpt {
+				t.Errorf("excerpt = %q, want %q", post.Excerpt, tt.excerpt)
+			}
+
+			// ignored categories are dropped, "email (Name)" gives the name
+			second := posts[1]
+			if len(second.Tags) != 0 || second.PublishedAt == nil {
+				t.Errorf("second post = %+v", second)
+			}
+			if tt.file == "rss.xml" && second.Author != "Trần B" {
+				t.Errorf("author = %q", second.Author)
+			}
+		})
+	}
+}
+
+func TestSourceConfig(t *testing.T) {
+	spec, err := SourceConfig{Name: "blog", Type: "feed", URL: "https://blog.example.com/feed"}.spec()
+	if err != nil || spec.Cron != "@every 6h0m0s" || spec.Jitter != 30*time.Minute {
+		t.Errorf("default spec = %+v, %v", spec, err)
+	}
+
+	invalid := []SourceConfig{
+		{Type: "feed", URL: "https://blog.example.com/feed"},
+		{Name: "blog", Type: "feed"},
+		{Name: "blog", Type: "sitemap", URL: "https://blog.example.com/sitemap.xml"},
+		{Name: "blog", Type: "feed", URL: "https://blog.example.com/feed", Jitter: "soon"},
+	}
+	for _, config := range invalid {
+		if _, err := config.source(); err == nil {
+			t.Errorf("source(%+v) succeeded", config)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package fixture records the pages fetched by the crawlers and serves them back,
+// so the parse logic of every source can be tested without the network
+package fixture
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// GoldenFile - posts expected from the fixtures of a source, as indented JSON
+const GoldenFile = "posts.json"
+
+var unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// Name - file name of the fixture of a URL: https://viblo.asia/trending?page=1 -> viblo.asia_trending_page_1.html
+func Name(rawURL string) string {
+	name := rawURL
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	}
+	return strings.Trim(unsafeChars.ReplaceAllString(name, "_"), "_") + ".html"
+}
+
+// Handler - serves the fixtures of dir, the page is chosen by the Host header and path
+// of the request as rewritten by Transport
+func Handler(dir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadFile(filepath.Join(dir, Name(r.Host+r.URL.RequestURI())))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(body)
+	})
+}
+
+// Transport - sends every request to the fixture server at serverURL, keeping the original host in the Host header
+func Transport(serverURL string) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		r := req.Clone(req.Context())
+		r.Host = req.URL.Host
+		r.URL.Scheme = "http"
+		r.URL.Host = strings.TrimPrefix(serverURL, "http://")
+		return http.DefaultTransport.RoundTrip(r)
+	})
+}
+
+// Recorder - fetches pages through next and saves the successful ones to dir
+type Recorder struct {
+	Dir  string
+	Next http.RoundTripper
+}
+
+func (rec *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rec.Next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if err := os.MkdirAll(rec.Dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(rec.Dir, Name(req.URL.String())), body, 0644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
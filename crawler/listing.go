@@ -0,0 +1,57 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+)
+
+// Mode - how far a crawl walks the listings of a source
+type Mode string
+
+const (
+	// Incremental - stops a listing after KnownStreak posts in a row that are already stored
+	Incremental Mode = "incremental"
+	// Full - walks every page, run as a periodic backfill
+	Full Mode = "full"
+)
+
+// DefaultKnownStreak - known posts in a row after which an incremental crawl stops a listing
+const DefaultKnownStreak = 10
+
+// Listing - pages of one listing of a source, newest first
+type Listing struct {
+	Pages []string
+	// Unordered - not sorted by date (e.g. trending), always walked entirely
+	Unordered bool
+}
+
+// Lister - sources with several listings, or whose pages depend on the mode.
+// The other sources have one listing made of their StartURLs
+type Lister interface {
+	Listings(ctx context.Context, mode Mode) []Listing
+}
+
+func listings(ctx context.Context, src Source, mode Mode) []Listing {
+	if lister, ok := src.(Lister); ok {
+		return lister.Listings(ctx, mode)
+	}
+	return []Listing{{Pages: src.StartURLs()}}
+}
+
+// pagesOf - every page of the listings, in order
+func pagesOf(listings []Listing) []string {
+	pages := []string{}
+	for _, listing := range listings {
+		pages = append(pages, listing.Pages...)
+	}
+	return pages
+}
+
+// numberedPages - format filled with the page numbers from first to last
+func numberedPages(format string, first, last int) []string {
+	pages := []string{}
+	for page := first; page <= last; page++ {
+		pages = append(pages, fmt.Sprintf(format, page))
+	}
+	return pages
+}
@@ -0,0 +1,58 @@
+package crawler
+
+import (
+	"devread/model"
+
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// maxExcerpt - excerpts are cut to this many characters
+const maxExcerpt = 300
+
+// parseTime - parses value with the first matching layout, nil if none matches
+func parseTime(value string, layouts ...string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// excerpt - collapses whitespace and cuts the text to maxExcerpt characters
+func excerpt(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= maxExcerpt {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:maxExcerpt])) + "…"
+}
+
+// articleMeta - fills the fields still empty from the Open Graph / article meta tags
+// of an article page and follows its <link rel=canonical>, e is the <html> element
+func articleMeta(e *colly.HTMLElement, post *model.Post) {
+	if canonical := e.ChildAttr(`link[rel="canonical"]`, "href"); canonical != "" {
+		post.Link = e.Request.AbsoluteURL(canonical)
+	}
+	if post.Excerpt == "" {
+		post.Excerpt = excerpt(e.ChildAttr(`meta[property="og:description"]`, "content"))
+	}
+	if post.CoverImage == "" {
+		post.CoverImage = e.ChildAttr(`meta[property="og:image"]`, "content")
+	}
+	if post.Author == "" {
+		post.Author = strings.TrimSpace(e.ChildAttr(`meta[name="author"]`, "content"))
+	}
+	if post.PublishedAt == nil {
+		post.PublishedAt = parseTime(e.ChildAttr(`meta[property="article:published_time"]`, "content"), time.RFC3339)
+	}
+}
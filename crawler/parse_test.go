@@ -0,0 +1,76 @@
+package crawler
+
+import (
+	"devread/crawler/fixture"
+	"devread/helper"
+
+	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// go test ./crawler -update rewrites the expected posts from the fixtures,
+// "devread fixtures record" refreshes the fixtures themselves from the real sites
+var update = flag.Bool("update", false, "ghi lại posts.json từ fixture")
+
+// serveFixtures - routes every crawler request of the test to the fixtures of a source
+func serveFixtures(t *testing.T, source string) {
+	server := httptest.NewServer(fixture.Handler(filepath.Join("testdata", source)))
+	helper.Transport = fixture.Transport(server.URL)
+	UsePolicy(Policy{UserAgent: "DevReadBot/test"})
+	t.Cleanup(func() {
+		helper.Transport = http.DefaultTransport
+		server.Close()
+	})
+}
+
+func TestParseFixtures(t *testing.T) {
+	for _, src := range Sources() {
+		src := src
+		t.Run(src.Name(), func(t *testing.T) {
+			serveFixtures(t, src.Name())
+
+			posts, err := src.Parse(context.Background(), src.StartURLs()[0])
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(posts) == 0 {
+				t.Fatal("no post parsed, a selector no longer matches the fixture")
+			}
+			for _, post := range posts {
+				if strings.TrimSpace(post.Name) == "" {
+					t.Errorf("post %q has no name", post.Link)
+				}
+				if !strings.HasPrefix(post.Link, "https://") {
+					t.Errorf("post %q has a relative link %q", post.Name, post.Link)
+				}
+			}
+
+			got, err := json.MarshalIndent(posts, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", src.Name(), fixture.GoldenFile)
+			if *update {
+				if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test ./crawler -update)", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("posts differ from %s:\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
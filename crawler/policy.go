@@ -0,0 +1,254 @@
+package crawler
+
+import (
+	"devread/custom_error"
+	"devread/helper"
+
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+	"go.uber.org/zap"
+)
+
+const (
+	// robotsTTL - how long a robots.txt is trusted before fetching it again
+	robotsTTL = 24 * time.Hour
+	// robotsRetry - a robots.txt that failed to load (network error, 5xx) allows everything
+	// and is fetched again after this
+	robotsRetry = 10 * time.Minute
+)
+
+// Policy - fetch rules shared by every source so the blogs we depend on don't ban us
+type Policy struct {
+	// UserAgent - identifies the crawler with a contact URL
+	UserAgent string
+	// Parallelism - requests in flight per host
+	Parallelism int
+	// Delay - minimum gap between two requests to a host, raised to the Crawl-delay of robots.txt
+	Delay time.Duration
+	// RandomDelay - random extra gap so requests don't follow a fixed rhythm
+	RandomDelay time.Duration
+	// IgnoreRobots - skip robots.txt, only for tests
+	IgnoreRobots bool
+	// Logger - warns about the robots.txt that can't be read, nil logs nothing
+	Logger *zap.Logger
+}
+
+// PolicyFromEnv - default policy overridden by CRAWL_USER_AGENT, CRAWL_CONTACT_URL,
+// CRAWL_PARALLELISM, CRAWL_DELAY and CRAWL_RANDOM_DELAY
+func PolicyFromEnv() (Policy, error) {
+	agent := os.Getenv("CRAWL_USER_AGENT")
+	if agent == "" {
+		agent = "DevReadBot/1.0"
+	}
+	contact := os.Getenv("CRAWL_CONTACT_URL")
+	if contact == "" {
+		contact = "https://github.com/dactoankmapydev/devread"
+	}
+
+	p := Policy{
+		UserAgent:   fmt.Sprintf("%s (+%s)", agent, contact),
+		Parallelism: 2,
+		Delay:       2 * time.Second,
+		RandomDelay: time.Second,
+	}
+	if value := os.Getenv("CRAWL_PARALLELISM"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("CRAWL_PARALLELISM không hợp lệ: %q", value)
+		}
+		p.Parallelism = n
+	}
+	for key, field := range map[string]*time.Duration{
+		"CRAWL_DELAY":        &p.Delay,
+		"CRAWL_RANDOM_DELAY": &p.RandomDelay,
+	} {
+		if value := os.Getenv(key); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return p, fmt.Errorf("%s không hợp lệ: %w", key, err)
+			}
+			*field = d
+		}
+	}
+	return p, nil
+}
+
+var (
+	policyMu sync.RWMutex
+	policy   = Policy{UserAgent: "DevReadBot/1.0", Parallelism: 1, Delay: time.Second}
+	hosts    = map[string]*hostState{}
+)
+
+// UsePolicy - applies p to every request made from now on, the robots.txt cache is dropped
+func UsePolicy(p Policy) {
+	if p.Parallelism < 1 {
+		p.Parallelism = 1
+	}
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	policy = p
+	hosts = map[string]*hostState{}
+}
+
+func currentPolicy() Policy {
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return policy
+}
+
+// hostState - robots.txt and request slots of one host, shared by every collector
+type hostState struct {
+	slots chan struct{}
+
+	mu   sync.Mutex
+	next time.Time
+
+	robotsMu  sync.Mutex
+	robots    *robotstxt.RobotsData
+	expiresAt time.Time
+}
+
+func host(name string) *hostState {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	h, ok := hosts[name]
+	if !ok {
+		h = &hostState{slots: make(chan struct{}, policy.Parallelism)}
+		hosts[name] = h
+	}
+	return h
+}
+
+// politeTransport - applies the policy to a request then sends it through helper.Transport
+type politeTransport struct{}
+
+func (politeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := currentPolicy()
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", p.UserAgent)
+
+	h := host(req.URL.Host)
+	delay := p.Delay
+	if !p.IgnoreRobots {
+		robots, err := h.robotsFor(req, p.UserAgent)
+		if err != nil {
+			return nil, err
+		}
+		if !robots.TestAgent(req.URL.RequestURI(), p.UserAgent) {
+			return nil, fmt.Errorf("%w: %s", custom_error.RobotsDisallowed, req.URL)
+		}
+		if crawlDelay := robots.FindGroup(p.UserAgent).CrawlDelay; crawlDelay > delay {
+			delay = crawlDelay
+		}
+	}
+
+	if err := h.acquire(req.Context()); err != nil {
+		return nil, err
+	}
+	defer h.release(delay, p.RandomDelay)
+	return helper.Transport.RoundTrip(req)
+}
+
+// acquire - waits for a free slot and for the delay since the last request to the host
+func (h *hostState) acquire(ctx context.Context) error {
+	select {
+	case h.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	h.mu.Lock()
+	wait := time.Until(h.next)
+	h.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		<-h.slots
+		return ctx.Err()
+	}
+}
+
+func (h *hostState) release(delay, randomDelay time.Duration) {
+	if randomDelay > 0 {
+		delay += time.Duration(rand.Int63n(int64(randomDelay)))
+	}
+	h.mu.Lock()
+	h.next = time.Now().Add(delay)
+	h.mu.Unlock()
+	<-h.slots
+}
+
+// robotsFor - robots.txt of the host, loaded once per robotsTTL
+func (h *hostState) robotsFor(req *http.Request, userAgent string) (*robotstxt.RobotsData, error) {
+	h.robotsMu.Lock()
+	defer h.robotsMu.Unlock()
+
+	if h.robots == nil || time.Now().After(h.expiresAt) {
+		robots, ttl, err := fetchRobots(req, userAgent, currentPolicy().Logger)
+		if err != nil {
+			return nil, err
+		}
+		h.robots, h.expiresAt = robots, time.Now().Add(ttl)
+	}
+	return h.robots, nil
+}
+
+// allowAll - rules used when robots.txt can't be read
+func allowAll() *robotstxt.RobotsData {
+	robots, _ := robotstxt.FromStatusAndBytes(http.StatusNotFound, nil)
+	return robots
+}
+
+// fetchRobots - a robots.txt that can't be read allows everything until robotsRetry:
+// a flaky robots.txt must not stall a whole source
+func fetchRobots(req *http.Request, userAgent string, logger *zap.Logger) (*robotstxt.RobotsData, time.Duration, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	robotsURL := req.URL.Scheme + "://" + req.URL.Host + "/robots.txt"
+	robotsReq, err := http.NewRequestWithContext(req.Context(), "GET", robotsURL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	robotsReq.Header.Set("User-Agent", userAgent)
+
+	resp, err := helper.Transport.RoundTrip(robotsReq)
+	if err != nil {
+		if req.Context().Err() != nil {
+			return nil, 0, req.Context().Err()
+		}
+		// unreachable robots.txt: crawl, the page request will fail the same way if the host is down
+		logger.Warn("Không đọc được robots.txt, tạm cho phép mọi trang ", zap.String("url", robotsURL),
+			zap.Duration("thử lại sau", robotsRetry), zap.Error(err))
+		return allowAll(), robotsRetry, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		logger.Warn("robots.txt lỗi, tạm cho phép mọi trang ", zap.String("url", robotsURL),
+			zap.Int("status", resp.StatusCode), zap.Duration("thử lại sau", robotsRetry))
+		return allowAll(), robotsRetry, nil
+	}
+	// 4xx allows everything
+	robots, err := robotstxt.FromResponse(resp)
+	if err != nil {
+		logger.Warn("robots.txt không hợp lệ, tạm cho phép mọi trang ", zap.String("url", robotsURL),
+			zap.Duration("thử lại sau", robotsRetry), zap.Error(err))
+		return allowAll(), robotsRetry, nil
+	}
+	return robots, robotsTTL, nil
+}
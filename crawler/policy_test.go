@@ -0,0 +1,110 @@
+package crawler
+
+import (
+	"devread/custom_error"
+	"devread/helper"
+
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoliteTransport(t *testing.T) {
+	var mu sync.Mutex
+	agents := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents = append(agents, r.UserAgent())
+		mu.Unlock()
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /private\nCrawl-delay: 0.2\n"))
+			return
+		}
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+	helper.Transport = http.DefaultTransport
+	UsePolicy(Policy{UserAgent: "DevReadBot/test (+https://example.com)"})
+
+	client := &http.Client{Transport: contextTransport{ctx: context.Background(), next: politeTransport{}}}
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL + "/posts")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		resp.Body.Close()
+	}
+	// the second request waits for the Crawl-delay of robots.txt
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("two requests took %v, want at least the 200ms crawl delay", elapsed)
+	}
+
+	_, err := client.Get(server.URL + "/private/page")
+	if !errors.Is(err, custom_error.RobotsDisallowed) {
+		t.Errorf("err = %v, want RobotsDisallowed", err)
+	}
+
+	// robots.txt once, then the two allowed pages
+	if len(agents) != 3 {
+		t.Fatalf("server got %d requests, want 3", len(agents))
+	}
+	for _, agent := range agents {
+		if agent != "DevReadBot/test (+https://example.com)" {
+			t.Errorf("User-Agent = %q", agent)
+		}
+	}
+}
+
+func TestRobotsDisallowedNotRetried(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		}
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+	helper.Transport = http.DefaultTransport
+	UsePolicy(Policy{UserAgent: "DevReadBot/test"})
+
+	start := time.Now()
+	_, err := newFetcher(context.Background()).Get(context.Background(), server.URL+"/private/page")
+	if !errors.Is(err, custom_error.RobotsDisallowed) {
+		t.Fatalf("err = %v, want RobotsDisallowed", err)
+	}
+	// the default fetcher waits a second before retrying
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Get took %v, the refusal was retried", elapsed)
+	}
+	if calls != 1 {
+		t.Errorf("server got %d requests, want only robots.txt", calls)
+	}
+}
+
+func TestRobotsServerErrorAllows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+	helper.Transport = http.DefaultTransport
+	UsePolicy(Policy{UserAgent: "DevReadBot/test"})
+
+	resp, err := newFetcher(context.Background()).Get(context.Background(), server.URL+"/posts")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,115 @@
+package crawler
+
+import (
+	"devread/handle_log"
+	"devread/model"
+	"devread/scheduler"
+
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"go.uber.org/zap"
+)
+
+const urlBase = "https://quan-cam.com"
+
+func init() {
+	Register(&quancamSource{})
+}
+
+type quancamSource struct{}
+
+func (s *quancamSource) Name() string {
+	return "quancam"
+}
+
+func (s *quancamSource) Schedule() scheduler.Spec {
+	return scheduler.Spec{
+		Cron:         scheduler.Every(96 * time.Hour),
+		Jitter:       time.Hour,
+		RunOnStartup: true,
+	}
+}
+
+func (s *quancamSource) StartURLs() []string {
+	return numberedPages(urlBase+"/posts?page=%d", 1, 4)
+}
+
+// Listings - the first pages for an incremental crawl, every page found
+// by following the "next" links for a full one
+func (s *quancamSource) Listings(ctx context.Context, mode Mode) []Listing {
+	if mode != Full {
+		return []Listing{{Pages: s.StartURLs()}}
+	}
+	return []Listing{{Pages: append([]string{urlBase + "/posts?page=1"}, GetListPage(ctx)...)}}
+}
+
+func (s *quancamSource) Parse(ctx context.Context, pageURL string) ([]model.Post, error) {
+	response, err := newFetcher(ctx).Get(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+	doc, err := goquery.NewDocumentFromReader(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]model.Post, 0)
+	doc.Find("div[class=post]").Each(func(i int, s *goquery.Selection) {
+		var quancamPost model.Post
+		quancamPost.Name = s.Find("h3.post__title > a").Text()
+		link, _ := s.Find("h3.post__title > a").Attr("href")
+		quancamPost.Link = urlBase + link
+		quancamPost.Tags = tagList(s.Find("span.tagging > a").Map(func(i int, a *goquery.Selection) string {
+			return strings.TrimPrefix(strings.TrimSpace(a.Text()), "#")
+		}))
+		if len(quancamPost.Tags) > 0 {
+			quancamPost.Tag = quancamPost.Tags[0]
+		}
+		quancamPost.PublishedAt = parseTime(s.Find("time").AttrOr("datetime", ""), time.RFC3339)
+		posts = append(posts, quancamPost)
+	})
+	return posts, nil
+}
+
+// GetListPage - follows the "next" links to list every page of quan-cam
+func GetListPage(ctx context.Context) []string {
+	log, _ := handle_log.WriteLog()
+
+	pageList := make([]string, 0)
+	page := []int{1}
+	for len(page) > 0 {
+		pathURL := fmt.Sprintf("%s/posts?page=%d", urlBase, page[0])
+		response, err := newFetcher(ctx).Get(ctx, pathURL)
+		if err != nil {
+			log.Error("Lỗi: ", zap.Error(err))
+			break
+		}
+
+		doc, err := goquery.NewDocumentFromReader(response.Body)
+		response.Body.Close()
+		if err != nil {
+			log.Error("Lỗi: ", zap.Error(err))
+			break
+		}
+
+		link, _ := doc.Find("a.next").Attr("href")
+		if link != "" {
+			split := strings.Split(link, "=")[1]
+			nextLink, _ := strconv.Atoi(split)
+			page[0] = nextLink
+			url := fmt.Sprintf("%s/posts?page=%d", urlBase, nextLink)
+			pageList = append(pageList, url)
+		} else {
+			page = page[:0]
+		}
+	}
+	log.Sugar().Info("Danh sách trang ", pageList)
+	return pageList
+}
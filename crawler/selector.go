@@ -0,0 +1,223 @@
+package crawler
+
+import (
+	"devread/model"
+	"devread/scheduler"
+
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/gocolly/colly/v2"
+)
+
+// Selectors - CSS selectors of a "selector" source. Item matches one post of a listing page,
+// the others are relative to it and read the text of their first match, "selector@attr" reads
+// an attribute instead and "@attr" an attribute of the item itself. Only an attribute name
+// ending the value is split off, a[href*="@"] is a plain selector
+type Selectors struct {
+	Item  string `json:"item" yaml:"item"`
+	Title string `json:"title" yaml:"title"`
+	// Link - the href attribute by default
+	Link string `json:"link" yaml:"link"`
+	// Tags - every match is a tag
+	Tags    string `json:"tags" yaml:"tags"`
+	Date    string `json:"date" yaml:"date"`
+	Author  string `json:"author" yaml:"author"`
+	Excerpt string `json:"excerpt" yaml:"excerpt"`
+	// Cover - the src attribute by default
+	Cover string `json:"cover" yaml:"cover"`
+}
+
+// Pagination - numbered pages of the start URLs containing {page}, from First (1 by default) to Last
+type Pagination struct {
+	First int `json:"first" yaml:"first"`
+	Last  int `json:"last" yaml:"last"`
+}
+
+// pagePlaceholder - replaced by the page number in the start URLs
+const pagePlaceholder = "{page}"
+
+// field - a parsed selector of Selectors
+type field struct {
+	css  string
+	attr string
+}
+
+// attrSuffix - "@attr" ending a selector, an @ elsewhere (e.g. a[href*="@"]) belongs to the selector
+var attrSuffix = regexp.MustCompile(`^(.*?)\s*@([A-Za-z_][-A-Za-z0-9_:.]*)$`)
+
+func parseField(value, defaultAttr string) (field, error) {
+	f := field{css: strings.TrimSpace(value), attr: defaultAttr}
+	if match := attrSuffix.FindStringSubmatch(f.css); match != nil {
+		f.css, f.attr = match[1], match[2]
+	}
+	if f.css != "" {
+		if _, err := cascadia.Compile(f.css); err != nil {
+			return f, fmt.Errorf("selector %q không hợp lệ: %w", value, err)
+		}
+	}
+	return f, nil
+}
+
+// selection - the item itself for an empty css
+func (f field) selection(item *goquery.Selection) *goquery.Selection {
+	if f.css == "" {
+		return item
+	}
+	return item.Find(f.css)
+}
+
+func (f field) value(s *goquery.Selection) string {
+	if f.attr != "" {
+		return strings.TrimSpace(s.AttrOr(f.attr, ""))
+	}
+	return strings.Join(strings.Fields(s.Text()), " ")
+}
+
+// first - value of the first match, empty for an unset field
+func (f field) first(item *goquery.Selection) string {
+	if f.css == "" && f.attr == "" {
+		return ""
+	}
+	return f.value(f.selection(item).First())
+}
+
+// all - values of every match
+func (f field) all(item *goquery.Selection) []string {
+	if f.css == "" && f.attr == "" {
+		return nil
+	}
+	values := []string{}
+	f.selection(item).Each(func(_ int, s *goquery.Selection) {
+		values = append(values, f.value(s))
+	})
+	return values
+}
+
+// selectorSource - a list page read with the CSS selectors of the sources file (see config.go),
+// the declarative version of the colly based sources such as thefullsnack or yellowcode
+type selectorSource struct {
+	name        string
+	schedule    scheduler.Spec
+	listings    []Listing
+	item        string
+	title       field
+	link        field
+	tags        field
+	date        field
+	author      field
+	excerpt     field
+	cover       field
+	dateFormats []string
+	ignore      []string
+}
+
+func newSelectorSource(config SourceConfig, spec scheduler.Spec) (*selectorSource, error) {
+	if config.Selectors == nil {
+		return nil, fmt.Errorf("thiếu selectors")
+	}
+	sel := config.Selectors
+	if sel.Item == "" || sel.Title == "" || sel.Link == "" {
+		return nil, fmt.Errorf("selectors cần item, title và link")
+	}
+	if _, err := cascadia.Compile(sel.Item); err != nil {
+		return nil, fmt.Errorf("selector %q không hợp lệ: %w", sel.Item, err)
+	}
+
+	s := &selectorSource{
+		name:        config.Name,
+		schedule:    spec,
+		item:        sel.Item,
+		dateFormats: config.DateFormats,
+		ignore:      config.IgnoreTags,
+	}
+	if len(s.dateFormats) == 0 {
+		s.dateFormats = []string{time.RFC3339}
+	}
+
+	fields := []struct {
+		target      *field
+		value       string
+		defaultAttr string
+	}{
+		{&s.title, sel.Title, ""},
+		{&s.link, sel.Link, "href"},
+		{&s.tags, sel.Tags, ""},
+		{&s.date, sel.Date, ""},
+		{&s.author, sel.Author, ""},
+		{&s.excerpt, sel.Excerpt, ""},
+		{&s.cover, sel.Cover, "src"},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		parsed, err := parseField(f.value, f.defaultAttr)
+		if err != nil {
+			return nil, err
+		}
+		*f.target = parsed
+	}
+
+	listings, err := config.listings()
+	if err != nil {
+		return nil, err
+	}
+	s.listings = listings
+	return s, nil
+}
+
+func (s *selectorSource) Name() string {
+	return s.name
+}
+
+func (s *selectorSource) Schedule() scheduler.Spec {
+	return s.schedule
+}
+
+func (s *selectorSource) StartURLs() []string {
+	return pagesOf(s.listings)
+}
+
+func (s *selectorSource) Listings(ctx context.Context, mode Mode) []Listing {
+	return s.listings
+}
+
+func (s *selectorSource) Parse(ctx context.Context, pageURL string) ([]model.Post, error) {
+	c := newCollector(ctx)
+
+	posts := []model.Post{}
+	c.OnHTML(s.item, func(e *colly.HTMLElement) {
+		posts = append(posts, s.post(e))
+	})
+
+	if err := c.Visit(pageURL); err != nil {
+		return posts, err
+	}
+	return posts, nil
+}
+
+func (s *selectorSource) post(e *colly.HTMLElement) model.Post {
+	post := model.Post{
+		Name:        s.title.first(e.DOM),
+		Tags:        tagList(s.tags.all(e.DOM), s.ignore...),
+		Author:      s.author.first(e.DOM),
+		Excerpt:     excerpt(s.excerpt.first(e.DOM)),
+		PublishedAt: parseTime(s.date.first(e.DOM), s.dateFormats...),
+	}
+	if len(post.Tags) > 0 {
+		post.Tag = post.Tags[0]
+	}
+	if link := s.link.first(e.DOM); link != "" {
+		post.Link = e.Request.AbsoluteURL(link)
+	}
+	if cover := s.cover.first(e.DOM); cover != "" {
+		post.CoverImage = e.Request.AbsoluteURL(cover)
+	}
+	return post
+}
@@ -0,0 +1,125 @@
+package crawler
+
+import (
+	"devread/crawler/fixture"
+	"devread/repository/repo_fake"
+	"devread/tagnorm"
+
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSelectorSource(t *testing.T) {
+	path := filepath.Join("testdata", "selector", "sources.yaml")
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, err := decodeSources(path, data)
+	if err != nil || len(configs) != 1 {
+		t.Fatalf("decodeSources = %+v, %v", configs, err)
+	}
+	src, err := configs[0].source()
+	if err != nil {
+		t.Fatalf("source: %v", err)
+	}
+	if pages := src.StartURLs(); len(pages) != 6 || pages[5] != "https://yellowcodebooks.com/category/lap-trinh-android/page/6" {
+		t.Errorf("StartURLs = %q", pages)
+	}
+
+	serveFixtures(t, "yellowcode")
+	results := DryRun(context.Background(), src, 1)
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("DryRun = %+v", results)
+	}
+
+	got, err := json.MarshalIndent(results[0].Posts, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ioutil.ReadFile(filepath.Join("testdata", "yellowcode", fixture.GoldenFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got)+"\n" != string(want) {
+		t.Errorf("posts differ from the yellowcode source:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSelectorConfig(t *testing.T) {
+	selectors := &Selectors{Item: "article", Title: "h2 > a", Link: "h2 > a"}
+	invalid := []SourceConfig{
+		{Name: "blog", Type: "selector", Selectors: selectors},
+		{Name: "blog", Type: "selector", URL: "https://blog.example.com/"},
+		{Name: "blog", Type: "selector", URL: "https://blog.example.com/", Selectors: &Selectors{Item: "article", Title: "h2"}},
+		{Name: "blog", Type: "selector", URL: "https://blog.example.com/", Selectors: &Selectors{Item: "article", Title: "h2[", Link: "a"}},
+		{Name: "blog", Type: "selector", URL: "https://blog.example.com/page/{page}", Selectors: selectors},
+		{Name: "blog", Type: "selector", URL: "https://blog.example.com/page/{page}", Selectors: selectors, Pagination: &Pagination{First: 3, Last: 2}},
+	}
+	for _, config := range invalid {
+		if _, err := config.source(); err == nil {
+			t.Errorf("source(%+v) succeeded", config)
+		}
+	}
+
+	fields := []struct {
+		value string
+		want  field
+	}{
+		{"h2 > a", field{css: "h2 > a", attr: "href"}},
+		{"time.entry-date@datetime", field{css: "time.entry-date", attr: "datetime"}},
+		{"@data-url", field{css: "", attr: "data-url"}},
+		{`a[href*="@"]`, field{css: `a[href*="@"]`, attr: "href"}},
+		{`a[href^="mailto:"][title="x@y"] @title`, field{css: `a[href^="mailto:"][title="x@y"]`, attr: "title"}},
+	}
+	for _, tt := range fields {
+		got, err := parseField(tt.value, "href")
+		if err != nil || got != tt.want {
+			t.Errorf("parseField(%q) = %+v, %v, want %+v", tt.value, got, err, tt.want)
+		}
+	}
+	if _, err := parseField("a@", "href"); err == nil {
+		t.Error(`parseField("a@") succeeded`)
+	}
+
+	if _, err := decodeSources("sources.json", []byte(`[{"name": "blog", "type": "selector", "selector": {}}]`)); err == nil {
+		t.Error("unknown field accepted")
+	}
+}
+
+func TestCrawlDropsIncompletePosts(t *testing.T) {
+	serveFixtures(t, "selector")
+	src, err := SourceConfig{
+		Name:      "blog",
+		Type:      "selector",
+		URL:       "https://blog.example.com/posts",
+		Selectors: &Selectors{Item: "li.post", Title: "h2", Link: "h2 > a", Tags: "span.tag"},
+	}.source()
+	if err != nil {
+		t.Fatalf("source: %v", err)
+	}
+
+	postRepo := repo_fake.NewPostRepo()
+	cr := &Crawler{
+		PostRepo:      postRepo,
+		CrawlRunRepo:  repo_fake.NewCrawlRunRepo(),
+		TagNormalizer: tagnorm.NewNormalizer(repo_fake.NewTagRepo(), zap.NewNop()),
+		Logger:        zap.NewNop(),
+	}
+	run := cr.Crawl(context.Background(), src, Incremental)
+
+	// still counted for drift detection
+	if run.PostsFound != 3 || run.EmptyLinks != 1 || run.EmptyNames != 1 {
+		t.Errorf("run = %+v, want 3 posts found, 1 without link and 1 without name", run)
+	}
+	posts := postRepo.Posts()
+	if len(posts) != 1 || !strings.HasSuffix(posts[0].Link, "/posts/hoc-go-trong-10-phut") {
+		t.Fatalf("stored %+v, want only the complete post", posts)
+	}
+}
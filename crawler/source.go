@@ -0,0 +1,61 @@
+package crawler
+
+import (
+	"devread/model"
+	"devread/scheduler"
+
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Source - a blog that can be crawled for posts
+type Source interface {
+	// Name - unique name of the source, e.g. "viblo"
+	Name() string
+	// StartURLs - listing pages visited on every crawl
+	StartURLs() []string
+	// Parse - extracts posts from one listing page
+	Parse(ctx context.Context, pageURL string) ([]model.Post, error)
+	// Schedule - default crawl schedule, can be overridden by env
+	Schedule() scheduler.Spec
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Source{}
+	names      []string
+)
+
+// Register - makes a source available to the crawler, usually called from init()
+func Register(src Source) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exist := registry[src.Name()]; exist {
+		panic(fmt.Sprintf("crawler: source %q đã được đăng ký", src.Name()))
+	}
+	registry[src.Name()] = src
+	names = append(names, src.Name())
+}
+
+// Sources - all registered sources in registration order
+func Sources() []Source {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	sources := make([]Source, 0, len(names))
+	for _, name := range names {
+		sources = append(sources, registry[name])
+	}
+	return sources
+}
+
+// Lookup - finds a registered source by name
+func Lookup(name string) (Source, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	src, ok := registry[name]
+	return src, ok
+}
@@ -0,0 +1,22 @@
+package crawler
+
+import "strings"
+
+// tagList - trims, lowercases and dedups tags, dropping empty ones and those in ignore
+func tagList(raw []string, ignore ...string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, ig := range ignore {
+		seen[strings.ToLower(ig)] = true
+	}
+
+	for _, tag := range raw {
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
@@ -1,23 +1,42 @@
 package crawler
 
 import (
-	"devread/custom_error"
-	"devread/handle_log"
-	"devread/helper"
 	"devread/model"
-	"devread/repository"
+	"devread/scheduler"
 
+	"context"
 	"github.com/gocolly/colly/v2"
-	"go.uber.org/zap"
 
-	"context"
 	"regexp"
 	"strings"
+	"time"
+	"unicode"
 )
 
-func ThefullsnackPost(postRepo repository.PostRepo) {
-	c := colly.NewCollector()
-	log, _ := handle_log.WriteLog()
+func init() {
+	Register(&thefullsnackSource{})
+}
+
+type thefullsnackSource struct{}
+
+func (s *thefullsnackSource) Name() string {
+	return "thefullsnack"
+}
+
+func (s *thefullsnackSource) Schedule() scheduler.Spec {
+	return scheduler.Spec{
+		Cron:         scheduler.Every(96 * time.Hour),
+		Jitter:       time.Hour,
+		RunOnStartup: true,
+	}
+}
+
+func (s *thefullsnackSource) StartURLs() []string {
+	return []string{"https://thefullsnack.com/"}
+}
+
+func (s *thefullsnackSource) Parse(ctx context.Context, pageURL string) ([]model.Post, error) {
+	c := newCollector(ctx)
 
 	posts := []model.Post{}
 	c.OnHTML("div[class=home-list-item]", func(e *colly.HTMLElement) {
@@ -30,59 +49,15 @@ func ThefullsnackPost(postRepo repository.PostRepo) {
 		splitName := strings.Join(regexSplitName.FindAllString(tags, -1), " ")
 		splitTime := strings.Join(regexSplitTime.FindAllString(splitName, -1), " ")
 		thefullsnackPost.Tag = strings.Replace(splitName, splitTime, "", -1)
+		thefullsnackPost.Tags = tagList(strings.FieldsFunc(thefullsnackPost.Tag, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		}))
+		thefullsnackPost.PublishedAt = parseTime(regexSplitTime.FindString(e.Text), "02-01-2006")
 		posts = append(posts, thefullsnackPost)
 	})
 
-	c.OnScraped(func(r *colly.Response) {
-		queue := helper.NewJobQueue(2)
-		queue.Start()
-		defer queue.Stop()
-
-		for _, post := range posts {
-			queue.Submit(&ThefullsnackProcess{
-				post:     post,
-				postRepo: postRepo,
-			})
-		}
-	})
-
-	c.OnError(func(r *colly.Response, err error) {
-		log.Error("Lỗi: ", zap.String("Truy cập ", r.Request.URL.String()), zap.Error(err))
-	})
-
-	c.Visit("https://thefullsnack.com/")
-}
-
-type ThefullsnackProcess struct {
-	post     model.Post
-	postRepo repository.PostRepo
-	logger   *zap.Logger
-}
-
-func (process *ThefullsnackProcess) Process() {
-	if process.logger == nil {
-		l, _ := handle_log.WriteLog()
-		process.logger = l
-	}
-
-	// select post by link
-	cacheRepo, err := process.postRepo.SelectByLink(context.Background(), process.post.Link)
-	if err == custom_error.PostNotFound {
-		// insert post to database
-		process.logger.Sugar().Info("Thêm bài viết: ", process.post.Name)
-		_, err = process.postRepo.Save(context.Background(), process.post)
-		if err != nil {
-			process.logger.Error("Thêm bài viết thất bại ", zap.String("bài viết: ", process.post.Name), zap.Error(err))
-		}
-		return
-	}
-
-	// update post
-	if process.post.Name != cacheRepo.Name {
-		process.logger.Sugar().Info("Cập nhật bài viết: ", process.post.Name)
-		_, err = process.postRepo.Update(context.Background(), process.post)
-		if err != nil {
-			process.logger.Error("Cập nhật bài viết thất bại ", zap.String("bài viết: ", process.post.Name), zap.Error(err))
-		}
+	if err := c.Visit(pageURL); err != nil {
+		return posts, err
 	}
+	return posts, nil
 }
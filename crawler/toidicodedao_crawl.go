@@ -1,11 +1,8 @@
 package crawler
 
 import (
-	"devread/custom_error"
-	"devread/handle_log"
-	"devread/helper"
 	"devread/model"
-	"devread/repository"
+	"devread/scheduler"
 
 	"context"
 	"fmt"
@@ -13,89 +10,78 @@ import (
 	"time"
 
 	"github.com/gocolly/colly/v2"
-	"go.uber.org/zap"
 )
 
-func ToidicodedaoPost(postRepo repository.PostRepo) {
-	c := colly.NewCollector()
-	log, _ := handle_log.WriteLog()
-	c.SetRequestTimeout(30 * time.Second)
-
-	posts := []model.Post{}
-	var toidicodedaoPost model.Post
-
-	c.OnHTML("footer[class=entry-meta]", func(e *colly.HTMLElement) {
-		if toidicodedaoPost.Name == "" || toidicodedaoPost.Link == "" {
-			return
-		}
-		toidicodedaoPost.Tag = strings.ToLower(e.ChildText("span.tag-links > a:last-child"))
-		posts = append(posts, toidicodedaoPost)
-	})
-
-	c.OnHTML(".site-content .entry-title", func(e *colly.HTMLElement) {
-		toidicodedaoPost.Name = e.Text
-		toidicodedaoPost.Link = e.ChildAttr("h1.entry-title > a", "href")
-		c.Visit(e.Request.AbsoluteURL(toidicodedaoPost.Link))
-		if toidicodedaoPost.Name == "" || toidicodedaoPost.Link == "" {
-			return
-		}
-		posts = append(posts, toidicodedaoPost)
-	})
+func init() {
+	Register(&toidicodedaoSource{})
+}
 
-	c.OnScraped(func(r *colly.Response) {
-		queue := helper.NewJobQueue(2)
-		queue.Start()
-		defer queue.Stop()
+type toidicodedaoSource struct{}
 
-		for _, post := range posts {
-			queue.Submit(&ToidicodedaoProcess{
-				post:     post,
-				postRepo: postRepo,
-			})
-		}
-	})
+func (s *toidicodedaoSource) Name() string {
+	return "toidicodedao"
+}
 
-	c.OnError(func(r *colly.Response, err error) {
-		log.Error("Lỗi: ", zap.String("Truy cập ", r.Request.URL.String()), zap.Error(err))
-	})
+func (s *toidicodedaoSource) Schedule() scheduler.Spec {
+	return scheduler.Spec{
+		Cron:         scheduler.Every(24 * time.Hour),
+		Jitter:       30 * time.Minute,
+		RunOnStartup: true,
+	}
+}
 
+func (s *toidicodedaoSource) StartURLs() []string {
+	listURL := []string{}
 	for i := 1; i < 32; i++ {
 		fullURL := fmt.Sprintf("https://toidicodedao.com/category/chuyen-coding/page/%d", i)
-		log.Sugar().Info("Truy cập: ", fullURL)
-		c.Visit(fullURL)
+		listURL = append(listURL, fullURL)
 	}
+	return listURL
 }
 
-type ToidicodedaoProcess struct {
-	post     model.Post
-	postRepo repository.PostRepo
-	logger   *zap.Logger
-}
+func (s *toidicodedaoSource) Parse(ctx context.Context, pageURL string) ([]model.Post, error) {
+	c := newCollector(ctx)
+	article := c.Clone()
 
-func (process *ToidicodedaoProcess) Process() {
-	if process.logger == nil {
-		l, _ := handle_log.WriteLog()
-		process.logger = l
-	}
+	posts := []model.Post{}
 
-	// select post by link
-	cacheRepo, err := process.postRepo.SelectByLink(context.Background(), process.post.Link)
-	if err == custom_error.PostNotFound {
-		// insert post to database
-		process.logger.Sugar().Info("Thêm bài viết: ", process.post.Name)
-		_, err = process.postRepo.Save(context.Background(), process.post)
-		if err != nil {
-			process.logger.Error("Thêm bài viết thất bại ", zap.String("bài viết: ", process.post.Name), zap.Error(err))
+	// article page: tag and metadata
+	article.OnHTML("html", func(e *colly.HTMLElement) {
+		toidicodedaoPost := model.Post{
+			Name:   e.Request.Ctx.Get("name"),
+			Link:   e.Request.Ctx.Get("link"),
+			Tag:    strings.ToLower(e.ChildText("footer[class=entry-meta] span.tag-links > a:last-child")),
+			Tags:   tagList(e.ChildTexts("footer[class=entry-meta] span.tag-links > a")),
+			Author: strings.TrimSpace(e.ChildText("span.author a")),
 		}
-		return
-	}
+		toidicodedaoPost.PublishedAt = parseTime(e.ChildAttr("time.entry-date", "datetime"), time.RFC3339)
+		articleMeta(e, &toidicodedaoPost)
+		posts = append(posts, toidicodedaoPost)
+	})
+
+	// keep the post found on the listing even if its article page fails
+	article.OnError(func(r *colly.Response, err error) {
+		posts = append(posts, model.Post{
+			Name: r.Ctx.Get("name"),
+			Link: r.Ctx.Get("link"),
+		})
+	})
 
-	// update post
-	if process.post.Name != cacheRepo.Name {
-		process.logger.Sugar().Info("Cập nhật bài viết: ", process.post.Name)
-		_, err = process.postRepo.Update(context.Background(), process.post)
-		if err != nil {
-			process.logger.Error("Cập nhật bài viết thất bại ", zap.String("bài viết: ", process.post.Name), zap.Error(err))
+	// listing page: name and link of each post
+	c.OnHTML(".site-content .entry-title", func(e *colly.HTMLElement) {
+		name := e.Text
+		link := e.ChildAttr("h1.entry-title > a", "href")
+		if name == "" || link == "" {
+			return
 		}
+		ctx := colly.NewContext()
+		ctx.Put("name", name)
+		ctx.Put("link", link)
+		article.Request("GET", e.Request.AbsoluteURL(link), nil, ctx, nil)
+	})
+
+	if err := c.Visit(pageURL); err != nil {
+		return posts, err
 	}
+	return posts, nil
 }
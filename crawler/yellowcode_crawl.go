@@ -1,23 +1,47 @@
 package crawler
 
 import (
-	"context"
-	"fmt"
 	"strings"
+	"time"
 
+	"context"
 	"github.com/gocolly/colly/v2"
-	"go.uber.org/zap"
 
-	"devread/custom_error"
-	"devread/handle_log"
-	"devread/helper"
 	"devread/model"
-	"devread/repository"
+	"devread/scheduler"
 )
 
-func YellowcodePost(postRepo repository.PostRepo) {
-	c := colly.NewCollector()
-	log, _ := handle_log.WriteLog()
+func init() {
+	Register(&yellowcodeSource{})
+}
+
+type yellowcodeSource struct{}
+
+func (s *yellowcodeSource) Name() string {
+	return "yellowcode"
+}
+
+func (s *yellowcodeSource) Schedule() scheduler.Spec {
+	return scheduler.Spec{
+		Cron:         scheduler.Every(96 * time.Hour),
+		Jitter:       time.Hour,
+		RunOnStartup: true,
+	}
+}
+
+func (s *yellowcodeSource) StartURLs() []string {
+	return pagesOf(s.Listings(context.Background(), Full))
+}
+
+func (s *yellowcodeSource) Listings(ctx context.Context, mode Mode) []Listing {
+	return []Listing{
+		{Pages: numberedPages("https://yellowcodebooks.com/category/lap-trinh-android/page/%d", 1, 6)},
+		{Pages: numberedPages("https://yellowcodebooks.com/category/lap-trinh-java/page/%d", 1, 5)},
+	}
+}
+
+func (s *yellowcodeSource) Parse(ctx context.Context, pageURL string) ([]model.Post, error) {
+	c := newCollector(ctx)
 
 	posts := []model.Post{}
 	var yellowcodePost model.Post
@@ -28,72 +52,18 @@ func YellowcodePost(postRepo repository.PostRepo) {
 			strings.Replace(
 				strings.Replace(
 					e.ChildText("span.meta-category > a"), "\n", "", -1), "/", "", -1), "-", "", -1))
-		posts = append(posts, yellowcodePost)
-	})
+		yellowcodePost.Tags = tagList(e.ChildTexts("span.meta-category > a"))
+		yellowcodePost.Author = strings.TrimSpace(e.ChildText("span.author a"))
+		yellowcodePost.PublishedAt = parseTime(e.ChildAttr("time.entry-date", "datetime"), time.RFC3339)
 
-	c.OnScraped(func(r *colly.Response) {
-		queue := helper.NewJobQueue(2)
-		queue.Start()
-		defer queue.Stop()
-
-		for _, post := range posts {
-			queue.Submit(&YellowcodeProcess{
-				post:     post,
-				postRepo: postRepo,
-			})
-		}
-	})
-
-	c.OnError(func(r *colly.Response, err error) {
-		log.Error("Lỗi: ", zap.String("Truy cập ", r.Request.URL.String()), zap.Error(err))
+		article := e.DOM.Closest("article")
+		yellowcodePost.Excerpt = excerpt(article.Find(".entry-summary p, .entry-content p").First().Text())
+		yellowcodePost.CoverImage = article.Find("img.wp-post-image").AttrOr("src", "")
+		posts = append(posts, yellowcodePost)
 	})
 
-	listURL := []string{}
-	for numb := 1; numb < 7; numb++ {
-		trend := fmt.Sprintf("https://yellowcodebooks.com/category/lap-trinh-android/page/%d", numb)
-		listURL = append(listURL, trend)
-	}
-	for numb := 1; numb < 6; numb++ {
-		newest := fmt.Sprintf("https://yellowcodebooks.com/category/lap-trinh-java/page/%d", numb)
-		listURL = append(listURL, newest)
-	}
-
-	for _, url := range listURL {
-		log.Sugar().Info("Truy cập: ", url)
-		c.Visit(url)
-	}
-}
-
-type YellowcodeProcess struct {
-	post     model.Post
-	postRepo repository.PostRepo
-	logger   *zap.Logger
-}
-
-func (process *YellowcodeProcess) Process() {
-	if process.logger == nil {
-		l, _ := handle_log.WriteLog()
-		process.logger = l
-	}
-
-	// select post by link
-	cacheRepo, err := process.postRepo.SelectByLink(context.Background(), process.post.Link)
-	if err == custom_error.PostNotFound {
-		// insert post to database
-		process.logger.Sugar().Info("Thêm bài viết: ", process.post.Name)
-		_, err = process.postRepo.Save(context.Background(), process.post)
-		if err != nil {
-			process.logger.Error("Thêm bài viết thất bại ", zap.String("bài viết: ", process.post.Name), zap.Error(err))
-		}
-		return
-	}
-
-	// update post
-	if process.post.Name != cacheRepo.Name {
-		process.logger.Sugar().Info("Cập nhật bài viết: ", process.post.Name)
-		_, err = process.postRepo.Update(context.Background(), process.post)
-		if err != nil {
-			process.logger.Error("Cập nhật bài viết thất bại ", zap.String("bài viết: ", process.post.Name), zap.Error(err))
-		}
+	if err := c.Visit(pageURL); err != nil {
+		return posts, err
 	}
+	return posts, nil
 }
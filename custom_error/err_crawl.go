@@ -0,0 +1,12 @@
+package custom_error
+
+import "errors"
+
+var (
+	CrawlRunInsertFail = errors.New("Lưu lịch sử crawl thất bại")
+	CrawlInterrupted   = errors.New("Crawl bị dừng giữa chừng")
+	RobotsDisallowed   = errors.New("robots.txt không cho phép truy cập")
+	TaskNotFound       = errors.New("Task không tồn tại")
+	TaskLocked         = errors.New("Task đang được chạy ở lượt khác")
+	TaskAlreadyRan     = errors.New("Task đã chạy trong lượt này")
+)
@@ -0,0 +1,41 @@
+package custom_error
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// StatusError - the server answered with a status outside 2xx
+type StatusError struct {
+	URL        string
+	StatusCode int
+	// RetryAfter - wait asked by the Retry-After header, zero when absent
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s trả về %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Temporary - 429 and 5xx may succeed later, other statuses won't
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// RetryError - every attempt failed, Err is the error of the last one
+type RetryError struct {
+	URL      string
+	Attempts int
+	Elapsed  time.Duration
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("request %s thất bại sau %d lần thử (%s): %v",
+		e.URL, e.Attempts, e.Elapsed.Round(time.Millisecond), e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
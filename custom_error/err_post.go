@@ -8,6 +8,13 @@ var (
 	PostNotFound   = errors.New("Bài viết không tồn tại")
 	PostConflict   = errors.New("Bài viết đã tồn tại")
 	PostInsertFail = errors.New("Thêm bài viết thất bại")
+	TagInsertFail  = errors.New("Lưu tag của bài viết thất bại")
+	InvalidCursor  = errors.New("Cursor không hợp lệ")
+	InvalidSort    = errors.New("Kiểu sắp xếp không hợp lệ")
+
+	//tag
+	TagAliasNotFound   = errors.New("Alias tag không tồn tại")
+	TagAliasInsertFail = errors.New("Lưu alias tag thất bại")
 
 	//bookmark
 	BookmarkNotFound = errors.New("Bookmark không tồn tại")
@@ -0,0 +1,114 @@
+package dedupe
+
+import (
+	"devread/custom_error"
+	"devread/helper"
+	"devread/model"
+	"devread/repository"
+
+	"context"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// maxDistance - names whose fingerprints differ in at most this many bits are the same article
+	maxDistance = 3
+	// minNameLength - shorter names ("Giới thiệu", "Docker") are too common to compare
+	minNameLength = 16
+)
+
+// Detector - groups an article cross-posted on several sources under its oldest post
+type Detector struct {
+	postRepo repository.PostRepo
+	logger   *zap.Logger
+}
+
+func NewDetector(postRepo repository.PostRepo, logger *zap.Logger) *Detector {
+	return &Detector{
+		postRepo: postRepo,
+		logger:   logger,
+	}
+}
+
+// Group - marks the post as a duplicate of the oldest post of another source with
+// a near-identical name, returns the id of that post or 0 if there is none
+func (d *Detector) Group(ctx context.Context, post model.Post) (int64, error) {
+	if utf8.RuneCountInString(post.Name) < minNameLength {
+		return 0, nil
+	}
+	hash := helper.Simhash(post.Name)
+	candidates, err := d.postRepo.SelectSimilar(ctx, post.Link, int64(hash))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, candidate := range candidates {
+		// candidates are oldest first, a post is never grouped under a newer one
+		if post.ID != 0 && candidate.ID > post.ID {
+			break
+		}
+		if candidate.Source == post.Source {
+			continue
+		}
+		if helper.HammingDistance(uint64(candidate.Simhash), hash) > maxDistance {
+			continue
+		}
+		if err := d.postRepo.MarkDuplicate(ctx, post.Link, candidate.ID); err != nil {
+			return 0, err
+		}
+		d.logger.Sugar().Info("Bài viết trùng: ", post.Link, " -> ", candidate.Link)
+		return candidate.ID, nil
+	}
+	return 0, nil
+}
+
+// Backfill - moves the posts already stored to their canonical link and groups duplicates,
+// returns how many links were changed and how many posts were grouped
+func (d *Detector) Backfill(ctx context.Context) (int, int, error) {
+	posts, err := d.postRepo.SelectDedupe(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	canonicalized, grouped := 0, 0
+	for _, post := range posts {
+		canonical := helper.CanonicalURL(post.Link)
+		if canonical != post.Link {
+			err := d.postRepo.UpdateLink(ctx, post.Link, canonical)
+			if err == custom_error.PostConflict {
+				// the canonical link is already stored: this row is the same page
+				primary, err := d.postRepo.SelectByLink(ctx, canonical)
+				if err != nil {
+					return canonicalized, grouped, err
+				}
+				if err := d.postRepo.MarkDuplicate(ctx, post.Link, primary.ID); err != nil {
+					return canonicalized, grouped, err
+				}
+				grouped++
+				continue
+			}
+			if err != nil {
+				return canonicalized, grouped, err
+			}
+			post.Link = canonical
+			canonicalized++
+		}
+
+		if err := d.postRepo.SaveSimhash(ctx, post.Link, int64(helper.Simhash(post.Name))); err != nil {
+			return canonicalized, grouped, err
+		}
+		if post.DuplicateOf != nil {
+			continue
+		}
+		primary, err := d.Group(ctx, post)
+		if err != nil {
+			return canonicalized, grouped, err
+		}
+		if primary != 0 {
+			grouped++
+		}
+	}
+	return canonicalized, grouped, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"devread/crawler"
+	"devread/crawler/fixture"
+	"devread/helper"
+
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+)
+
+// fixtures - "record" fetches the first start page of each source (and the pages it follows)
+// into the crawler test fixtures and writes the posts parsed from them as the expected result,
+// the fixtures of a source are left untouched when its record fails
+func fixtures(ctx context.Context, log *zap.Logger, args []string) int {
+	if len(args) == 0 || args[0] != "record" {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	flags := flag.NewFlagSet("fixtures record", flag.ContinueOnError)
+	dir := flags.String("dir", filepath.Join("crawler", "testdata"), "thư mục fixture")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if err := loadSources(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	sources := crawler.Sources()
+	if flags.NArg() > 0 {
+		sources = []crawler.Source{}
+		for _, name := range flags.Args() {
+			src, ok := crawler.Lookup(name)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Nguồn %q không tồn tại\n", name)
+				return 2
+			}
+			sources = append(sources, src)
+		}
+	}
+
+	policy, err := crawler.PolicyFromEnv()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	policy.Logger = log
+	crawler.UsePolicy(policy)
+
+	status := 0
+	for _, src := range sources {
+		if err := record(ctx, src, filepath.Join(*dir, src.Name())); err != nil {
+			log.Error("Ghi fixture thất bại ", zap.String("source", src.Name()), zap.Error(err))
+			status = 1
+			continue
+		}
+		fmt.Printf("Đã ghi fixture của %s\n", src.Name())
+	}
+	return status
+}
+
+// record - fetches into a temporary directory next to dir, the fixtures of dir are
+// replaced only once the page was fetched and parsed into posts
+func record(ctx context.Context, src crawler.Source, dir string) error {
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempDir(filepath.Dir(dir), "."+filepath.Base(dir)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	helper.Transport = &fixture.Recorder{Dir: tmp, Next: http.DefaultTransport}
+	defer func() { helper.Transport = http.DefaultTransport }()
+
+	posts, err := src.Parse(ctx, src.StartURLs()[0])
+	if err != nil {
+		return err
+	}
+	if len(posts) == 0 {
+		return fmt.Errorf("không có bài viết nào, fixture cũ được giữ nguyên")
+	}
+	data, err := json.MarshalIndent(posts, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmp, fixture.GoldenFile), append(data, '\n'), 0644); err != nil {
+		return err
+	}
+
+	// swap the directories, the old fixtures are restored if the new ones can't be moved in
+	old := tmp + ".old"
+	if err := os.Rename(dir, old); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		os.Rename(old, dir)
+		return err
+	}
+	return os.RemoveAll(old)
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"devread/model"
+	"devread/repository"
+
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"go.uber.org/zap"
+)
+
+type CrawlHandler struct {
+	CrawlRunRepo repository.CrawlRunRepo
+	Logger       *zap.Logger
+}
+
+// CrawlRuns godoc
+// @Summary Get crawl run history
+// @Tags crawl
+// @Accept  json
+// @Produce  json
+// @Security jwt
+// @Param source query string false "source name, e.g. viblo"
+// @Param limit query int false "max runs returned (default 50)"
+// @Success 200 {object} model.Response
+// @Failure 403 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /admin/crawl/runs [get]
+func (crawl *CrawlHandler) CrawlRuns(c echo.Context) error {
+	limit := 50
+	if value := c.QueryParam("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 || n > 500 {
+			return c.JSON(http.StatusBadRequest, model.Response{
+				StatusCode: http.StatusBadRequest,
+				Message:    "Lỗi cú pháp",
+			})
+		}
+		limit = n
+	}
+
+	runs, err := crawl.CrawlRunRepo.SelectAll(c.Request().Context(), c.QueryParam("source"), limit)
+	if err != nil {
+		crawl.Logger.Error("Lỗi khi chọn lịch sử crawl ", zap.Error(err))
+		return c.JSON(http.StatusNotFound, model.Response{
+			StatusCode: http.StatusNotFound,
+			Message:    "Không tìm thấy lịch sử crawl",
+		})
+	}
+	return c.JSON(http.StatusOK, model.Response{
+		StatusCode: http.StatusOK,
+		Message:    "Xử lý thành công",
+		Data:       runs,
+	})
+}
+
+// CrawlHealth godoc
+// @Summary Get crawl health of each source
+// @Tags crawl
+// @Accept  json
+// @Produce  json
+// @Security jwt
+// @Success 200 {object} model.Response
+// @Failure 403 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /admin/crawl/health [get]
+func (crawl *CrawlHandler) CrawlHealth(c echo.Context) error {
+	health, err := crawl.CrawlRunRepo.SelectHealth(c.Request().Context())
+	if err != nil {
+		crawl.Logger.Error("Lỗi khi chọn tình trạng crawl ", zap.Error(err))
+		return c.JSON(http.StatusNotFound, model.Response{
+			StatusCode: http.StatusNotFound,
+			Message:    "Không tìm thấy lịch sử crawl",
+		})
+	}
+
+	for i := range health {
+		health[i].Status = sourceStatus(health[i])
+	}
+	return c.JSON(http.StatusOK, model.Response{
+		StatusCode: http.StatusOK,
+		Message:    "Xử lý thành công",
+		Data:       health,
+	})
+}
+
+// sourceStatus - "empty" when the last run found nothing (selectors likely broken),
+// "drift" when it differs from the previous runs (see crawler/drift.go),
+// "degraded" when it found less than half of the recent average of runs in the same mode
+// (incremental runs stop early and find far fewer posts than full ones), "error" when it failed
+func sourceStatus(h model.SourceHealth) string {
+	switch {
+	case h.LastPostsFound == 0:
+		return "empty"
+	case len(h.LastAnomalies) > 0:
+		return "drift"
+	case float64(h.LastPostsFound) < h.AvgPostsFound/2:
+		return "degraded"
+	case h.LastErrorCount > 0:
+		return "error"
+	}
+	return "ok"
+}
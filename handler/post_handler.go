@@ -1,13 +1,17 @@
 package handler
 
 import (
+	"devread/custom_error"
 	"devread/model"
 	"devread/model/req"
 	"devread/repository"
+	"devread/tagnorm"
 
 	"net/http"
+	"strconv"
+	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
@@ -20,22 +24,55 @@ func GetQueryTag(r *http.Request) string {
 }
 
 type PostHandler struct {
-	PostRepo     repository.PostRepo
-	AuthRepo     repository.AuthenRepo
-	BookmarkRepo repository.BookmarkRepo
-	Logger       *zap.Logger
+	PostRepo      repository.PostRepo
+	TrendRepo     repository.TrendRepo
+	ClickRepo     repository.ClickRepo
+	AuthRepo      repository.AuthenRepo
+	BookmarkRepo  repository.BookmarkRepo
+	TagNormalizer *tagnorm.Normalizer
+	Logger        *zap.Logger
 }
 
 // PostTrending godoc
-// @Summary Get all posts trending
+// @Summary Get posts trending, ranked by recency, bookmarks and the source's own trending page
 // @Tags post
 // @Accept  json
 // @Produce  json
+// @Param window query string false "24h, 7d (default) or 30d"
+// @Param limit query int false "posts per page (default 20, max 100)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "trending (default), newest, title or source"
 // @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
 // @Failure 404 {object} model.Response
 // @Router /trend [get]
 func (post *PostHandler) PostTrending(c echo.Context) error {
-	repos, err := post.PostRepo.SelectAll(c.Request().Context())
+	window := c.QueryParam("window")
+	if window == "" {
+		window = model.DefaultTrendWindow
+	}
+	if _, ok := model.LookupTrendWindow(window); !ok {
+		return c.JSON(http.StatusBadRequest, model.Response{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Khoảng thời gian không hợp lệ",
+		})
+	}
+
+	page, err := pageQuery(c, model.SortTrending)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.Response{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Lỗi cú pháp",
+		})
+	}
+
+	result, err := post.TrendRepo.SelectPosts(c.Request().Context(), window, page)
+	if err == custom_error.InvalidCursor || err == custom_error.InvalidSort {
+		return c.JSON(http.StatusBadRequest, model.Response{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+		})
+	}
 	if err != nil {
 		post.Logger.Error("Lỗi khi chọn tất cả bài đăng thịnh hành ", zap.Error(err))
 		return c.JSON(http.StatusNotFound, model.Response{
@@ -46,7 +83,8 @@ func (post *PostHandler) PostTrending(c echo.Context) error {
 	return c.JSON(http.StatusOK, model.Response{
 		StatusCode: http.StatusOK,
 		Message:    "Xử lý thành công",
-		Data:       repos,
+		Data:       result.Posts,
+		NextCursor: result.NextCursor,
 	})
 }
 
@@ -56,11 +94,30 @@ func (post *PostHandler) PostTrending(c echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Param tag query string true "tag of posts"
+// @Param limit query int false "posts per page (default 20, max 100)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "newest (default), title or source"
 // @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
 // @Failure 404 {object} model.Response
 // @Router /posts [get]
 func (post *PostHandler) SearchPost(c echo.Context) error {
-	repos, err := post.PostRepo.SelectByTag(c.Request().Context(), GetQueryTag(c.Request()))
+	page, err := pageQuery(c, model.SortNewest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.Response{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Lỗi cú pháp",
+		})
+	}
+
+	tag := post.TagNormalizer.Canonical(GetQueryTag(c.Request()))
+	result, err := post.PostRepo.SelectByTag(c.Request().Context(), tag, page)
+	if err == custom_error.InvalidCursor || err == custom_error.InvalidSort {
+		return c.JSON(http.StatusBadRequest, model.Response{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+		})
+	}
 	if err != nil {
 		post.Logger.Error("Không tìm thấy bài viết theo tag ", zap.Error(err))
 		return c.JSON(http.StatusNotFound, model.Response{
@@ -71,10 +128,176 @@ func (post *PostHandler) SearchPost(c echo.Context) error {
 	return c.JSON(http.StatusOK, model.Response{
 		StatusCode: http.StatusOK,
 		Message:    "Xử lý thành công",
-		Data:       repos,
+		Data:       result.Posts,
+		NextCursor: result.NextCursor,
 	})
 }
 
+// pageQuery - limit, cursor and sort of a listing, with their defaults
+func pageQuery(c echo.Context, defaultSort string) (model.PageQuery, error) {
+	req := req.ReqPage{}
+	if err := c.Bind(&req); err != nil {
+		return model.PageQuery{}, err
+	}
+
+	page := model.PageQuery{
+		Limit:  req.Limit,
+		Cursor: req.Cursor,
+		Sort:   req.Sort,
+	}
+	if page.Limit <= 0 || page.Limit > 100 {
+		page.Limit = 20
+	}
+	if page.Sort == "" {
+		page.Sort = defaultSort
+	}
+	return page, nil
+}
+
+// Search godoc
+// @Summary Full-text search posts by name, tags and excerpt
+// @Tags post
+// @Accept  json
+// @Produce  json
+// @Param q query string true "search terms"
+// @Param source query string false "source name, e.g. viblo"
+// @Param tag query string false "tag of posts"
+// @Param from query string false "published from (YYYY-MM-DD)"
+// @Param to query string false "published before (YYYY-MM-DD)"
+// @Param limit query int false "max results (default 20, max 100)"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /search [get]
+func (post *PostHandler) Search(c echo.Context) error {
+	req := req.ReqSearch{}
+	if err := c.Bind(&req); err != nil {
+		post.Logger.Error("Lỗi cú pháp ", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, model.Response{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Lỗi cú pháp",
+		})
+	}
+
+	// validate thông tin gửi lên
+	if err := c.Validate(req); err != nil {
+		post.Logger.Error("Lỗi cú pháp ", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, model.Response{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Lỗi cú pháp",
+		})
+	}
+
+	query := model.SearchQuery{
+		Query:  req.Query,
+		Source: req.Source,
+		Limit:  req.Limit,
+	}
+	if req.Tag != "" {
+		query.Tag = post.TagNormalizer.Canonical(req.Tag)
+	}
+	if query.Limit <= 0 || query.Limit > 100 {
+		query.Limit = 20
+	}
+
+	var err error
+	if query.From, err = parseDate(req.From); err != nil {
+		return c.JSON(http.StatusBadRequest, model.Response{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Ngày không hợp lệ",
+		})
+	}
+	if query.To, err = parseDate(req.To); err != nil {
+		return c.JSON(http.StatusBadRequest, model.Response{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Ngày không hợp lệ",
+		})
+	}
+
+	results, err := post.PostRepo.Search(c.Request().Context(), query)
+	if err != nil {
+		post.Logger.Error("Tìm kiếm bài viết thất bại ", zap.Error(err))
+		return c.JSON(http.StatusNotFound, model.Response{
+			StatusCode: http.StatusNotFound,
+			Message:    "Không tìm thấy bài viết",
+		})
+	}
+	return c.JSON(http.StatusOK, model.Response{
+		StatusCode: http.StatusOK,
+		Message:    "Xử lý thành công",
+		Data:       results,
+	})
+}
+
+// parseDate - YYYY-MM-DD query value, nil when empty
+func parseDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// sessionCookie - identifies an anonymous visitor so repeated clicks are counted once
+const sessionCookie = "devread_sid"
+
+// Go godoc
+// @Summary Count a click on a post and redirect to it
+// @Tags post
+// @Param id path int true "id of post"
+// @Success 302
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /go/{id} [get]
+func (post *PostHandler) Go(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.Response{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Lỗi cú pháp",
+		})
+	}
+
+	repo, err := post.PostRepo.SelectByID(c.Request().Context(), id)
+	if err != nil {
+		post.Logger.Error("Không tìm thấy bài viết ", zap.Int64("id", id), zap.Error(err))
+		return c.JSON(http.StatusNotFound, model.Response{
+			StatusCode: http.StatusNotFound,
+			Message:    "Không tìm thấy bài viết",
+		})
+	}
+
+	// a lost click must not keep the user from the post
+	if _, err := post.ClickRepo.Record(repo.ID, visitor(c)); err != nil {
+		post.Logger.Error("Ghi nhận lượt click thất bại ", zap.Int64("id", id), zap.Error(err))
+	}
+	return c.Redirect(http.StatusFound, repo.Link)
+}
+
+// visitor - the signed in user, or the anonymous session that gets a cookie on its first click
+func visitor(c echo.Context) string {
+	if token, ok := c.Get("user").(*jwt.Token); ok {
+		if claims, ok := token.Claims.(*model.TokenDetails); ok {
+			return "user:" + claims.UserID
+		}
+	}
+
+	if cookie, err := c.Cookie(sessionCookie); err == nil && cookie.Value != "" {
+		return "anon:" + cookie.Value
+	}
+	session := uuid.New().String()
+	c.SetCookie(&http.Cookie{
+		Name:     sessionCookie,
+		Value:    session,
+		Path:     "/go",
+		HttpOnly: true,
+	})
+	return "anon:" + session
+}
+
 // SelectBookmarks godoc
 // @Summary Get list bookmark
 // @Tags bookmark
@@ -113,6 +336,7 @@ func (post *PostHandler) SelectBookmarks(c echo.Context) error {
 // @Success 200 {object} model.Response
 // @Failure 400 {object} model.Response
 // @Failure 403 {object} model.Response
+// @Failure 404 {object} model.Response
 // @Failure 409 {object} model.Response
 // @Router /user/bookmark/add [post]
 func (post *PostHandler) Bookmark(c echo.Context) error {
@@ -148,9 +372,15 @@ func (post *PostHandler) Bookmark(c echo.Context) error {
 	err = post.BookmarkRepo.Bookmark(
 		c.Request().Context(),
 		bId.String(),
-		req.PostName,
+		req.PostID,
 		claims.UserID)
 
+	if err == custom_error.PostNotFound {
+		return c.JSON(http.StatusNotFound, model.Response{
+			StatusCode: http.StatusNotFound,
+			Message:    "Không tìm thấy bài viết",
+		})
+	}
 	if err != nil {
 		post.Logger.Error("Đánh dấu repo mới thất bại ", zap.Error(err))
 		return c.JSON(http.StatusConflict, model.Response{
@@ -201,7 +431,7 @@ func (post *PostHandler) DelBookmark(c echo.Context) error {
 
 	err = post.BookmarkRepo.Delete(
 		c.Request().Context(),
-		req.PostName, claims.UserID)
+		req.PostID, claims.UserID)
 
 	if err != nil {
 		post.Logger.Error("Lỗi khi xóa bookmark ", zap.Error(err))
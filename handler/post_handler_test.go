@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"devread/middleware"
+	"devread/model"
+	"devread/repository/repo_fake"
+	"devread/security"
+
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+func TestGoRecordsVisitor(t *testing.T) {
+	os.Setenv("ACCESS_SECRET", "test-secret")
+	defer os.Unsetenv("ACCESS_SECRET")
+
+	postRepo := repo_fake.NewPostRepo()
+	result, err := postRepo.UpsertMany(context.Background(), []model.Post{{Name: "Học Go", Link: "https://viblo.asia/p/hoc-go"}})
+	if err != nil || len(result.Inserted) != 1 {
+		t.Fatalf("UpsertMany = %+v, %v", result, err)
+	}
+	clickRepo := repo_fake.NewClickRepo()
+	handler := &PostHandler{PostRepo: postRepo, ClickRepo: clickRepo, Logger: zap.NewNop()}
+
+	e := echo.New()
+	e.GET("/go/:id", handler.Go, middleware.OptionalJWTMiddleware())
+
+	token, err := security.CreateToken(model.User{UserID: "u-42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedIn := httptest.NewRequest(http.MethodGet, "/go/1", nil)
+	signedIn.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	anonymous := httptest.NewRequest(http.MethodGet, "/go/1", nil)
+
+	for _, req := range []*http.Request{signedIn, anonymous} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusFound || rec.Header().Get(echo.HeaderLocation) != "https://viblo.asia/p/hoc-go" {
+			t.Fatalf("GET /go/1 = %d %q", rec.Code, rec.Header().Get(echo.HeaderLocation))
+		}
+	}
+
+	clicks := clickRepo.Clicks()
+	if len(clicks) != 2 || clicks[0].Visitor != "user:u-42" {
+		t.Fatalf("clicks = %+v, want the signed in user first", clicks)
+	}
+	if clicks[1].Visitor == "user:u-42" || len(clicks[1].Visitor) <= len("anon:") || clicks[1].Visitor[:5] != "anon:" {
+		t.Errorf("anonymous visitor = %q", clicks[1].Visitor)
+	}
+}
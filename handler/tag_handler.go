@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"devread/custom_error"
+	"devread/helper"
+	"devread/model"
+	"devread/model/req"
+	"devread/repository"
+	"devread/tagnorm"
+
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"go.uber.org/zap"
+)
+
+type TagHandler struct {
+	TagRepo       repository.TagRepo
+	TagNormalizer *tagnorm.Normalizer
+	Logger        *zap.Logger
+}
+
+// TagAliases godoc
+// @Summary Get list tag alias
+// @Tags tag
+// @Accept  json
+// @Produce  json
+// @Security jwt
+// @Success 200 {object} model.Response
+// @Failure 403 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /admin/tags/aliases [get]
+func (tag *TagHandler) TagAliases(c echo.Context) error {
+	aliases, err := tag.TagRepo.SelectAliases(c.Request().Context())
+	if err != nil {
+		tag.Logger.Error("Lỗi khi chọn alias tag ", zap.Error(err))
+		return c.JSON(http.StatusNotFound, model.Response{
+			StatusCode: http.StatusNotFound,
+			Message:    "Không tìm thấy alias tag",
+		})
+	}
+	return c.JSON(http.StatusOK, model.Response{
+		StatusCode: http.StatusOK,
+		Message:    "Xử lý thành công",
+		Data:       aliases,
+	})
+}
+
+// SaveTagAlias godoc
+// @Summary Add or change tag alias
+// @Tags tag
+// @Accept  json
+// @Produce  json
+// @Security jwt
+// @Param data body req.ReqTagAlias true "alias"
+// @Success 200 {object} model.Response
+// @Failure 403 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /admin/tags/aliases [post]
+func (tag *TagHandler) SaveTagAlias(c echo.Context) error {
+	req := req.ReqTagAlias{}
+	if err := c.Bind(&req); err != nil {
+		tag.Logger.Error("Lỗi cú pháp ", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, model.Response{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Lỗi cú pháp",
+		})
+	}
+
+	// validate thông tin gửi lên
+	if err := c.Validate(req); err != nil {
+		tag.Logger.Error("Lỗi cú pháp ", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, model.Response{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Lỗi cú pháp",
+		})
+	}
+
+	alias := model.TagAlias{
+		Alias: helper.Slugify(req.Alias),
+		Tag:   helper.Slugify(req.Tag),
+	}
+	if alias.Alias == "" || alias.Tag == "" || alias.Alias == alias.Tag {
+		return c.JSON(http.StatusBadRequest, model.Response{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Alias tag không hợp lệ",
+		})
+	}
+
+	alias, err := tag.TagRepo.SaveAlias(c.Request().Context(), alias)
+	if err != nil {
+		tag.Logger.Error("Lưu alias tag thất bại ", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, model.Response{
+			StatusCode: http.StatusInternalServerError,
+			Message:    err.Error(),
+		})
+	}
+	tag.reload(c)
+
+	return c.JSON(http.StatusOK, model.Response{
+		StatusCode: http.StatusOK,
+		Message:    "Lưu alias tag thành công",
+		Data:       alias,
+	})
+}
+
+// DelTagAlias godoc
+// @Summary Delete tag alias
+// @Tags tag
+// @Accept  json
+// @Produce  json
+// @Security jwt
+// @Param alias path string true "alias"
+// @Success 200 {object} model.Response
+// @Failure 403 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /admin/tags/aliases/{alias} [delete]
+func (tag *TagHandler) DelTagAlias(c echo.Context) error {
+	err := tag.TagRepo.DeleteAlias(c.Request().Context(), helper.Slugify(c.Param("alias")))
+	if err != nil {
+		tag.Logger.Error("Lỗi khi xóa alias tag ", zap.Error(err))
+		if err == custom_error.TagAliasNotFound {
+			return c.JSON(http.StatusNotFound, model.Response{
+				StatusCode: http.StatusNotFound,
+				Message:    err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, model.Response{
+			StatusCode: http.StatusInternalServerError,
+			Message:    "Xoá alias tag thất bại",
+		})
+	}
+	tag.reload(c)
+
+	return c.JSON(http.StatusOK, model.Response{
+		StatusCode: http.StatusOK,
+		Message:    "Xoá alias tag thành công",
+	})
+}
+
+func (tag *TagHandler) reload(c echo.Context) {
+	if err := tag.TagNormalizer.Reload(c.Request().Context()); err != nil {
+		tag.Logger.Error("Tải alias tag thất bại ", zap.Error(err))
+	}
+}
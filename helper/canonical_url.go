@@ -0,0 +1,66 @@
+package helper
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams - query parameters added by ad and social networks, besides utm_*.
+// Generic names such as ref or amp may select another page and are kept
+var trackingParams = map[string]bool{
+	"fbclid": true,
+	"gclid":  true,
+}
+
+// httpsHosts - hosts known to serve every page over https, their http links are upgraded
+var httpsHosts = map[string]bool{
+	"viblo.asia":          true,
+	"toidicodedao.com":    true,
+	"yellowcodebooks.com": true,
+	"thefullsnack.com":    true,
+	"quan-cam.com":        true,
+	"codeaholicguy.com":   true,
+}
+
+// CanonicalURL - one spelling per page: lowercase host, https for httpsHosts, no default port,
+// fragment, tracking parameters or trailing slash, remaining parameters sorted.
+// Returns raw unchanged if it is not an absolute URL
+func CanonicalURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	switch {
+	case u.Scheme == "https":
+		u.Host = strings.TrimSuffix(u.Host, ":443")
+	case u.Scheme == "http":
+		u.Host = strings.TrimSuffix(u.Host, ":80")
+		if httpsHosts[strings.TrimPrefix(u.Host, "www.")] {
+			u.Scheme = "https"
+		}
+	}
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	if u.Path != "/" {
+		u.Path = strings.TrimRight(u.Path, "/")
+		u.RawPath = strings.TrimRight(u.RawPath, "/")
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") || trackingParams[strings.ToLower(key)] {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode()
+	u.ForceQuery = false
+	return u.String()
+}
@@ -0,0 +1,27 @@
+package helper
+
+import "testing"
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"https://Viblo.asia/p/go-context-abc/?utm_source=fb&utm_medium=post#comments", "https://viblo.asia/p/go-context-abc"},
+		{"http://toidicodedao.com:80/2021/06/07/hoc-go/?fbclid=x", "https://toidicodedao.com/2021/06/07/hoc-go"},
+		{"https://blog.example.com:443/posts?b=2&a=1&gclid=y", "https://blog.example.com/posts?a=1&b=2"},
+		// unknown hosts keep their scheme, the https version may not exist
+		{"http://blog.example.com/hoc-go/", "http://blog.example.com/hoc-go"},
+		{"http://blog.example.com:8080/hoc-go", "http://blog.example.com:8080/hoc-go"},
+		// ref, share and amp select another page on some sites
+		{"https://blog.example.com/posts?ref=main", "https://blog.example.com/posts?ref=main"},
+		{"https://blog.example.com/hoc-go?amp=1", "https://blog.example.com/hoc-go?amp=1"},
+		{"https://forum.example.com/thread?share=42&utm_campaign=x", "https://forum.example.com/thread?share=42"},
+		{"/p/relative", "/p/relative"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalURL(tt.raw); got != tt.want {
+			t.Errorf("CanonicalURL(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -1,54 +1,200 @@
 package helper
 
 import (
+	"devread/custom_error"
 	"devread/handle_log"
+
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/cenkalti/backoff"
 	"go.uber.org/zap"
 )
 
-func getRequest(pathURL string) (*http.Response, error) {
-	log, _ := handle_log.WriteLog()
+// Transport - round tripper of every crawler request, tests replace it to serve fixtures
+var Transport http.RoundTripper = http.DefaultTransport
 
-	req, _ := http.NewRequest("GET", pathURL, nil)
-	client := &http.Client{}
-	resp, err := client.Do(req)
+const (
+	DefaultMaxElapsedTime  = 2 * time.Minute
+	DefaultInitialInterval = time.Second
+	DefaultMaxInterval     = 30 * time.Second
+	DefaultAttemptTimeout  = 30 * time.Second
+)
 
-	if err != nil {
-		log.Error("Phản hồi Không mong đợi ", zap.Int("Status Code", resp.StatusCode), zap.Error(err))
-		return nil, err
-	}
-	return resp, nil
-}
+// Fetcher - GET with exponential backoff, retrying network errors, 429 and 5xx
+// until MaxElapsedTime, other errors and statuses fail at once
+type Fetcher struct {
+	// Client - nil uses helper.Transport
+	Client          *http.Client
+	MaxElapsedTime  time.Duration
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	// AttemptTimeout - deadline of one attempt, reading the body included
+	AttemptTimeout time.Duration
+	Logger         *zap.Logger
 
-func GetRequestWithRetries(api string) (*http.Response, error) {
-	var err error
-	var resp *http.Response
+	// sleep - waits between attempts, replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
 
+// NewFetcher - fetcher with the default limits
+func NewFetcher() *Fetcher {
 	log, _ := handle_log.WriteLog()
+	return &Fetcher{
+		MaxElapsedTime:  DefaultMaxElapsedTime,
+		InitialInterval: DefaultInitialInterval,
+		MaxInterval:     DefaultMaxInterval,
+		AttemptTimeout:  DefaultAttemptTimeout,
+		Logger:          log,
+	}
+}
+
+// GetRequestWithRetries - GET with the default fetcher, gives up when ctx is cancelled
+func GetRequestWithRetries(ctx context.Context, api string) (*http.Response, error) {
+	return NewFetcher().Get(ctx, api)
+}
 
+// Get - returns a 2xx response, a *custom_error.StatusError for a status not worth retrying,
+// a *custom_error.RetryError when MaxElapsedTime is spent, ctx.Err() when cancelled
+// or any other error as is
+func (f *Fetcher) Get(ctx context.Context, pathURL string) (*http.Response, error) {
 	bo := backoff.NewExponentialBackOff()
-	bo.MaxInterval = 5 * time.Minute
+	bo.InitialInterval = f.InitialInterval
+	bo.MaxInterval = f.MaxInterval
+	// the deadline is checked below so that a long Retry-After counts too
+	bo.MaxElapsedTime = 0
+	bo.Reset()
 
-	for {
-		resp, err = getRequest(api)
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		resp, err := f.getRequest(ctx, pathURL)
 		if err == nil {
-			break
+			return resp, nil
 		}
-		d := bo.NextBackOff()
-		if d == backoff.Stop {
-			log.Debug("Hết thời gian thử lại")
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
-		log.Error("Request lỗi ", zap.Error(err))
-		log.Sugar().Info("Thử lại trong ", d)
-		time.Sleep(d)
+		if !retryable(err) {
+			return nil, err
+		}
+		statusErr, isStatus := err.(*custom_error.StatusError)
+
+		wait := bo.NextBackOff()
+		if isStatus && statusErr.RetryAfter > wait {
+			wait = statusErr.RetryAfter
+		}
+		elapsed := time.Since(start)
+		if elapsed+wait > f.MaxElapsedTime {
+			return nil, &custom_error.RetryError{URL: pathURL, Attempts: attempt, Elapsed: elapsed, Err: err}
+		}
+
+		f.Logger.Warn("Request lỗi, thử lại ", zap.String("url", pathURL), zap.Duration("sau", wait), zap.Error(err))
+		if err := f.wait(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable - 429, 5xx and network errors, including an attempt past its deadline.
+// A page refused by robots.txt stays refused
+func retryable(err error) bool {
+	if errors.Is(err, custom_error.RobotsDisallowed) {
+		return false
+	}
+	if statusErr, ok := err.(*custom_error.StatusError); ok {
+		return statusErr.Temporary()
+	}
+	// *url.Error is a net.Error whatever it wraps, look at the cause
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
 	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func (f *Fetcher) getRequest(ctx context.Context, pathURL string) (*http.Response, error) {
+	timeout := f.AttemptTimeout
+	if timeout <= 0 {
+		timeout = DefaultAttemptTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 
-	// Tất cả các lần thử lại không thành công
+	req, err := http.NewRequestWithContext(ctx, "GET", pathURL, nil)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
-	return resp, nil
+	client := f.Client
+	if client == nil {
+		client = &http.Client{Transport: Transport}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		resp.Body = cancelBody{ReadCloser: resp.Body, cancel: cancel}
+		return resp, nil
+	}
+
+	// drain so the connection is reused by the next attempt
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	cancel()
+	return nil, &custom_error.StatusError{
+		URL:        pathURL,
+		StatusCode: resp.StatusCode,
+		RetryAfter: retryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// cancelBody - releases the deadline of the attempt once the body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+func (f *Fetcher) wait(ctx context.Context, d time.Duration) error {
+	if f.sleep != nil {
+		return f.sleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryAfter - the Retry-After header as a duration, it holds either seconds or an HTTP date
+func retryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
 }
@@ -0,0 +1,218 @@
+package helper
+
+import (
+	"devread/custom_error"
+
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// testFetcher - fetcher that records its waits instead of sleeping
+func testFetcher(waits *[]time.Duration) *Fetcher {
+	return &Fetcher{
+		Client:          &http.Client{},
+		MaxElapsedTime:  time.Second,
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     2 * time.Second,
+		Logger:          zap.NewNop(),
+		sleep: func(ctx context.Context, d time.Duration) error {
+			*waits = append(*waits, d)
+			return ctx.Err()
+		},
+	}
+}
+
+// statusServer - answers the given statuses in order, then 200
+func statusServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		if n <= len(statuses) {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestFetcherRetriesServerErrors(t *testing.T) {
+	server, calls := statusServer(t, nil, http.StatusServiceUnavailable, http.StatusBadGateway)
+	var waits []time.Duration
+
+	resp, err := testFetcher(&waits).Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if *calls != 3 || len(waits) != 2 {
+		t.Fatalf("calls = %d, waits = %v, want 3 calls and 2 waits", *calls, waits)
+	}
+}
+
+func TestFetcherDoesNotRetryClientErrors(t *testing.T) {
+	server, calls := statusServer(t, nil, http.StatusNotFound)
+	var waits []time.Duration
+
+	_, err := testFetcher(&waits).Get(context.Background(), server.URL)
+	var statusErr *custom_error.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("err = %v, want a 404 StatusError", err)
+	}
+	if *calls != 1 {
+		t.Fatalf("calls = %d, want 1", *calls)
+	}
+}
+
+func TestFetcherHonorsRetryAfter(t *testing.T) {
+	server, _ := statusServer(t, http.Header{"Retry-After": {"0"}}, http.StatusTooManyRequests)
+	var waits []time.Duration
+	f := testFetcher(&waits)
+
+	resp, err := f.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+
+	// longer than the backoff
+	server, _ = statusServer(t, http.Header{"Retry-After": {"1"}}, http.StatusTooManyRequests)
+	waits = nil
+	f.MaxElapsedTime = 5 * time.Second
+	resp, err = f.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if len(waits) != 1 || waits[0] != time.Second {
+		t.Fatalf("waits = %v, want [1s]", waits)
+	}
+
+	// beyond the max elapsed time: give up instead of waiting
+	server, calls := statusServer(t, http.Header{"Retry-After": {"3600"}}, http.StatusTooManyRequests)
+	waits = nil
+	_, err = f.Get(context.Background(), server.URL)
+	var retryErr *custom_error.RetryError
+	if !errors.As(err, &retryErr) || *calls != 1 || len(waits) != 0 {
+		t.Fatalf("err = %v, calls = %d, waits = %v", err, *calls, waits)
+	}
+}
+
+func TestFetcherGivesUp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	var waits []time.Duration
+
+	_, err := testFetcher(&waits).Get(context.Background(), server.URL)
+	var retryErr *custom_error.RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("err = %v, want RetryError", err)
+	}
+	var statusErr *custom_error.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("RetryError does not wrap the last status: %v", err)
+	}
+	if retryErr.Attempts != len(waits)+1 {
+		t.Fatalf("attempts = %d, waits = %d", retryErr.Attempts, len(waits))
+	}
+}
+
+func TestFetcherRetriesNetworkErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	var waits []time.Duration
+
+	_, err := testFetcher(&waits).Get(context.Background(), server.URL)
+	var retryErr *custom_error.RetryError
+	if !errors.As(err, &retryErr) || len(waits) == 0 {
+		t.Fatalf("err = %v, waits = %v, want retries then RetryError", err, waits)
+	}
+}
+
+// roundTripFunc - transport failing without touching the network
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestFetcherDoesNotRetryOtherErrors(t *testing.T) {
+	refused := errors.New("bị chính sách từ chối")
+	var waits []time.Duration
+	f := testFetcher(&waits)
+	f.Client = &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, refused
+	})}
+
+	if _, err := f.Get(context.Background(), "https://viblo.asia/newest"); !errors.Is(err, refused) || len(waits) != 0 {
+		t.Fatalf("err = %v, waits = %v, want the transport error at once", err, waits)
+	}
+	// the request can't even be built
+	if _, err := f.Get(context.Background(), "://viblo.asia"); err == nil || len(waits) != 0 {
+		t.Fatalf("err = %v, waits = %v, want the URL error at once", err, waits)
+	}
+}
+
+func TestFetcherAttemptTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	var waits []time.Duration
+	f := testFetcher(&waits)
+	f.AttemptTimeout = 20 * time.Millisecond
+
+	_, err := f.Get(context.Background(), server.URL)
+	var retryErr *custom_error.RetryError
+	if !errors.As(err, &retryErr) || !errors.Is(err, context.DeadlineExceeded) || len(waits) == 0 {
+		t.Fatalf("err = %v, waits = %v, want timed out attempts then RetryError", err, waits)
+	}
+}
+
+func TestFetcherCancelled(t *testing.T) {
+	server, _ := statusServer(t, nil, http.StatusServiceUnavailable)
+	ctx, cancel := context.WithCancel(context.Background())
+	var waits []time.Duration
+	f := testFetcher(&waits)
+	f.sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}
+
+	if _, err := f.Get(ctx, server.URL); err != context.Canceled {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"-1", 0},
+		{"soon", 0},
+		{"Tue, 01 Jun 2021 10:00:30 GMT", 30 * time.Second},
+		{"Tue, 01 Jun 2021 09:00:00 GMT", 0},
+	}
+	for _, tt := range tests {
+		if got := retryAfter(tt.value, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
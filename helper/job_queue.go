@@ -1,17 +1,21 @@
 package helper
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
 //https://riptutorial.com/go/example/18325/job-queue-with-worker-pool
 //https://gist.github.com/harlow/dbcd639cf8d396a2ab73
 // Job - interface for job processing
 type Job interface {
-	Process()
+	Process(ctx context.Context)
 }
 
 // Worker - the worker threads that actually process the jobs
 type Worker struct {
-	done             sync.WaitGroup
+	ctx              context.Context
+	done             *sync.WaitGroup
 	readyPool        chan chan Job
 	assignedJobQueue chan Job
 
@@ -20,27 +24,29 @@ type Worker struct {
 
 // JobQueue - a queue for enqueueing jobs to be processed
 type JobQueue struct {
+	ctx               context.Context
 	internalQueue     chan Job
 	readyPool         chan chan Job
 	workers           []*Worker
-	dispatcherStopped sync.WaitGroup
-	workersStopped    sync.WaitGroup
+	dispatcherStopped *sync.WaitGroup
+	workersStopped    *sync.WaitGroup
 	quit              chan bool
 }
 
-// NewJobQueue - creates a new job queue
-func NewJobQueue(maxWorkers int) *JobQueue {
-	workersStopped := sync.WaitGroup{}
+// NewJobQueue - creates a new job queue, ctx is handed to every job and stops accepting new ones when cancelled
+func NewJobQueue(ctx context.Context, maxWorkers int) *JobQueue {
+	workersStopped := &sync.WaitGroup{}
 	readyPool := make(chan chan Job, maxWorkers)
 	workers := make([]*Worker, maxWorkers, maxWorkers)
 	for i := 0; i < maxWorkers; i++ {
-		workers[i] = NewWorker(readyPool, workersStopped)
+		workers[i] = NewWorker(ctx, readyPool, workersStopped)
 	}
 	return &JobQueue{
+		ctx:               ctx,
 		internalQueue:     make(chan Job),
 		readyPool:         readyPool,
 		workers:           workers,
-		dispatcherStopped: sync.WaitGroup{},
+		dispatcherStopped: &sync.WaitGroup{},
 		workersStopped:    workersStopped,
 		quit:              make(chan bool),
 	}
@@ -51,6 +57,7 @@ func (q *JobQueue) Start() {
 	for i := 0; i < len(q.workers); i++ {
 		q.workers[i].Start()
 	}
+	q.dispatcherStopped.Add(1)
 	go q.dispatch()
 }
 
@@ -61,7 +68,6 @@ func (q *JobQueue) Stop() {
 }
 
 func (q *JobQueue) dispatch() {
-	q.dispatcherStopped.Add(1)
 	for {
 		select {
 		case job := <-q.internalQueue: // We got something in on our queue
@@ -78,14 +84,20 @@ func (q *JobQueue) dispatch() {
 	}
 }
 
-// Submit - adds a new job to be processed
-func (q *JobQueue) Submit(job Job) {
-	q.internalQueue <- job
+// Submit - adds a new job to be processed, fails once the context of the queue is cancelled
+func (q *JobQueue) Submit(job Job) error {
+	select {
+	case q.internalQueue <- job:
+		return nil
+	case <-q.ctx.Done():
+		return q.ctx.Err()
+	}
 }
 
 // NewWorker - creates a new worker
-func NewWorker(readyPool chan chan Job, done sync.WaitGroup) *Worker {
+func NewWorker(ctx context.Context, readyPool chan chan Job, done *sync.WaitGroup) *Worker {
 	return &Worker{
+		ctx:              ctx,
 		done:             done,
 		readyPool:        readyPool,
 		assignedJobQueue: make(chan Job),
@@ -95,13 +107,13 @@ func NewWorker(readyPool chan chan Job, done sync.WaitGroup) *Worker {
 
 // Start - begins the job processing loop for the worker
 func (w *Worker) Start() {
+	w.done.Add(1)
 	go func() {
-		w.done.Add(1)
 		for {
 			w.readyPool <- w.assignedJobQueue // check the job queue in
 			select {
 			case job := <-w.assignedJobQueue: // see if anything has been assigned to the queue
-				job.Process()
+				job.Process(w.ctx)
 			case <-w.quit:
 				w.done.Done()
 				return
@@ -0,0 +1,65 @@
+package helper
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// shingleSize - titles are short, so they are compared by character shingles rather than words
+const shingleSize = 4
+
+// Simhash - 64 bit fingerprint of a title, near-identical titles differ in few bits.
+// Case, diacritics and punctuation are ignored
+func Simhash(text string) uint64 {
+	words := strings.FieldsFunc(Fold(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	runes := []rune(strings.Join(words, " "))
+	if len(runes) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	for i := 0; i+shingleSize <= len(runes) || i == 0; i++ {
+		end := i + shingleSize
+		if end > len(runes) {
+			end = len(runes)
+		}
+		h := fnv.New64a()
+		h.Write([]byte(string(runes[i:end])))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			hash |= 1 << uint(bit)
+		}
+	}
+	return hash
+}
+
+// HammingDistance - number of bits two fingerprints differ in
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// SimhashBands - the fingerprint cut in 4 parts of 16 bits: two fingerprints
+// at distance 3 or less share at least one part
+func SimhashBands(hash uint64) [4]int64 {
+	return [4]int64{
+		int64(hash >> 48 & 0xffff),
+		int64(hash >> 32 & 0xffff),
+		int64(hash >> 16 & 0xffff),
+		int64(hash & 0xffff),
+	}
+}
@@ -0,0 +1,112 @@
+package helper
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Unaccent - removes Vietnamese diacritics: "sử dụng" -> "su dung"
+func Unaccent(text string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(t, text)
+	if err != nil {
+		return text
+	}
+	return strings.NewReplacer("đ", "d", "Đ", "D").Replace(result)
+}
+
+// Slugify - lowercase, unaccented, words joined by "-": "Lập Trình Go" -> "lap-trinh-go".
+// Keeps '+', '#' and '.' so "c++", "c#" and ".net" stay distinct
+func Slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(Unaccent(text)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '+', r == '#', r == '.':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		default:
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// Fold - lowercase and unaccented, used for diacritic-insensitive matching
+func Fold(text string) string {
+	return strings.ToLower(Unaccent(text))
+}
+
+// foldRune - Fold of a single rune, so folded text keeps the rune positions of the original
+func foldRune(r rune) rune {
+	folded := []rune(Fold(string(r)))
+	if len(folded) != 1 {
+		return unicode.ToLower(r)
+	}
+	return folded[0]
+}
+
+// SearchTerms - folded words of a search query, without operators and excluded (-word) terms
+func SearchTerms(query string) []string {
+	terms := []string{}
+	for _, field := range strings.Fields(Fold(query)) {
+		if strings.HasPrefix(field, "-") || field == "or" {
+			continue
+		}
+		terms = append(terms, strings.FieldsFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+	return terms
+}
+
+// Highlight - wraps in <mark> the words of text starting with one of the terms,
+// ignoring case and diacritics: Highlight("Sử dụng context", ["su"]) -> "<mark>Sử</mark> dụng context".
+// The result is HTML: the text is escaped, only the <mark> tags are left as markup
+func Highlight(text string, terms []string) string {
+	runes := []rune(text)
+	folded := make([]rune, len(runes))
+	for i, r := range runes {
+		folded[i] = foldRune(r)
+	}
+
+	marked := make([]bool, len(runes))
+	for _, term := range terms {
+		t := []rune(term)
+		if len(t) == 0 {
+			continue
+		}
+		for i := 0; i+len(t) <= len(folded); i++ {
+			if i > 0 && (unicode.IsLetter(folded[i-1]) || unicode.IsDigit(folded[i-1])) {
+				continue
+			}
+			if string(folded[i:i+len(t)]) != term {
+				continue
+			}
+			// mark the whole word
+			for j := i; j < len(folded) && (unicode.IsLetter(folded[j]) || unicode.IsDigit(folded[j])); j++ {
+				marked[j] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range runes {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString("<mark>")
+		}
+		b.WriteString(html.EscapeString(string(r)))
+		if marked[i] && (i == len(runes)-1 || !marked[i+1]) {
+			b.WriteString("</mark>")
+		}
+	}
+	return b.String()
+}
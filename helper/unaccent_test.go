@@ -0,0 +1,23 @@
+package helper
+
+import "testing"
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text  string
+		terms []string
+		want  string
+	}{
+		{"Sử dụng context", []string{"su"}, "<mark>Sử</mark> dụng context"},
+		{"Học Go trong 10 phút", []string{"go", "phut"}, "Học <mark>Go</mark> trong 10 <mark>phút</mark>"},
+		{"Hướng dẫn <script>alert(1)</script>", []string{"script"},
+			"Hướng dẫn &lt;<mark>script</mark>&gt;alert(1)&lt;/<mark>script</mark>&gt;"},
+		{`<img src=x onerror="alert('xss')"> & React`, []string{"react"},
+			"&lt;img src=x onerror=&#34;alert(&#39;xss&#39;)&#34;&gt; &amp; <mark>React</mark>"},
+	}
+	for _, tt := range tests {
+		if got := Highlight(tt.text, tt.terms); got != tt.want {
+			t.Errorf("Highlight(%q, %q) = %q, want %q", tt.text, tt.terms, got, tt.want)
+		}
+	}
+}
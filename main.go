@@ -1,21 +1,19 @@
 package main
 
 import (
-	"devread/crawler"
 	"devread/db"
 	_ "devread/docs"
 	"devread/handle_log"
-	"devread/handler"
-	"devread/helper"
-	"devread/repository/repo_impl"
-	"devread/router"
 
+	"context"
+	"fmt"
 	"os"
-	"time"
+	"os/signal"
+	"syscall"
 
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
-	"github.com/labstack/echo/v4"
-	echoSwagger "github.com/swaggo/echo-swagger"
+	"go.uber.org/zap"
 )
 
 func init() {
@@ -24,6 +22,18 @@ func init() {
 	}
 }
 
+const usage = `Cách dùng:
+  devread [serve] [--with-crawler]   chạy API (mặc định)
+  devread crawl [source...]          chạy crawler theo lịch (worker)
+  devread crawl --once [source...]   crawl một lần rồi thoát
+  devread crawl --once --full        crawl lại toàn bộ các trang một lần rồi thoát
+  devread tags backfill              chuẩn hoá các tag đã lưu theo bảng alias
+  devread posts dedupe               chuẩn hoá link và gộp các bài viết trùng đã lưu
+  devread fixtures record [source...] ghi lại HTML test của crawler từ trang thật
+  devread sources dry-run [--file sources.yaml] [--pages n] [--json] <source>
+                                     in bài viết một nguồn đọc được mà không lưu
+`
+
 // @title DevRead API
 // @version 1.0
 // @description Ứng dụng tổng hợp kiến thức cho developer
@@ -40,6 +50,39 @@ func main() {
 	// write log
 	log, _ := handle_log.WriteLog()
 
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		command, args = args[0], args[1:]
+	}
+
+	// cancelled on SIGTERM (heroku restart) or Ctrl+C, every command stops its work and returns
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	os.Exit(run(ctx, stop, log, command, args))
+}
+
+func run(ctx context.Context, stop context.CancelFunc, log *zap.Logger, command string, args []string) int {
+	defer stop()
+
+	switch command {
+	case "serve":
+		return serve(ctx, log, args)
+	case "crawl":
+		return crawl(ctx, log, args)
+	case "tags":
+		return tags(ctx, log, args)
+	case "posts":
+		return posts(ctx, log, args)
+	case "fixtures":
+		return fixtures(ctx, log, args)
+	case "sources":
+		return sources(ctx, log, args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+}
+
+func connectRedis(log *zap.Logger) *db.RedisDB {
 	// // Dùng dưới local
 	// redisHost := os.Getenv("REDIS_HOST")
 	// redisPort := os.Getenv("REDIS_PORT")
@@ -47,13 +90,6 @@ func main() {
 	// Dùng trên server heroku
 	redisUrl := os.Getenv("REDIS_URL")
 
-	// postgres details
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	password := os.Getenv("DB_PASSWORD")
-	username := os.Getenv("DB_USERNAME")
-	dbname := os.Getenv("DB_NAME")
-
 	// connect redis
 	client := &db.RedisDB{
 		// Dùng dưới local
@@ -66,6 +102,16 @@ func main() {
 		Logger: log,
 	}
 	client.NewRedisDB()
+	return client
+}
+
+func connectPostgres(log *zap.Logger) *db.Sql {
+	// postgres details
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	password := os.Getenv("DB_PASSWORD")
+	username := os.Getenv("DB_USERNAME")
+	dbname := os.Getenv("DB_NAME")
 
 	// connect postgres
 	sql := &db.Sql{
@@ -77,79 +123,14 @@ func main() {
 		Logger:   log,
 	}
 	sql.Connect()
-	defer sql.Close()
-
-	e := echo.New()
-	e.GET("/swagger/*", echoSwagger.WrapHandler)
-
-	customValidator := helper.NewCustomValidator()
-	customValidator.RegisterValidate()
-
-	e.Validator = customValidator
-
-	userHandler := handler.UserHandler{
-		UserRepo: repo_impl.NewUserRepo(sql),
-		AuthRepo: repo_impl.NewAuthenRepo(client),
-		Logger:   log,
-	}
-
-	postHandler := handler.PostHandler{
-		PostRepo:     repo_impl.NewPostRepo(sql),
-		AuthRepo:     repo_impl.NewAuthenRepo(client),
-		BookmarkRepo: repo_impl.NewBookmarkRepo(sql),
-		Logger:       log,
-	}
-
-	api := router.API{
-		Echo:        e,
-		UserHandler: userHandler,
-		PostHandler: postHandler,
-	}
-	api.SetupRouter()
-
-	// time start crawler
-	go crawler.VibloPost(postHandler.PostRepo)
-	go crawler.ToidicodedaoPost(postHandler.PostRepo)
-	go crawler.ThefullsnackPost(postHandler.PostRepo)
-	go crawler.QuancamPostV1(postHandler.PostRepo)
-	go crawler.CodeaholicguyPost(postHandler.PostRepo)
-	go crawler.YellowcodePost(postHandler.PostRepo)
-
-	// schedule crawler
-	go schedule(60*time.Minute, postHandler, 1)
-	go schedule(24*time.Hour, postHandler, 2)
-	go schedule(96*time.Hour, postHandler, 3)
-	go schedule(96*time.Hour, postHandler, 4)
-	go schedule(96*time.Hour, postHandler, 5)
-	go schedule(96*time.Hour, postHandler, 6)
-
-	e.Logger.Fatal(e.Start(":" + os.Getenv("PORT")))
+	return sql
 }
 
-func schedule(timeSchedule time.Duration, handler handler.PostHandler, crowIlnndex int) {
-	ticker := time.NewTicker(timeSchedule)
-	func() {
-		for {
-			switch crowIlnndex {
-			case 1:
-				<-ticker.C
-				crawler.VibloPost(handler.PostRepo)
-			case 2:
-				<-ticker.C
-				crawler.ToidicodedaoPost(handler.PostRepo)
-			case 3:
-				<-ticker.C
-				crawler.ThefullsnackPost(handler.PostRepo)
-			case 4:
-				<-ticker.C
-				crawler.QuancamPostV1(handler.PostRepo)
-			case 5:
-				<-ticker.C
-				crawler.CodeaholicguyPost(handler.PostRepo)
-			case 6:
-				<-ticker.C
-				crawler.YellowcodePost(handler.PostRepo)
-			}
-		}
-	}()
+// instanceID - identifies this process as the owner of crawl locks
+func instanceID() string {
+	name := os.Getenv("DYNO")
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	return name + "-" + uuid.New().String()
 }
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"devread/model"
+
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+// AdminMiddleware - lets through only the users listed in ADMIN_USER_IDS (comma separated),
+// must come after JWTMiddleware. Without the variable every admin route is forbidden
+func AdminMiddleware() echo.MiddlewareFunc {
+	admins := map[string]bool{}
+	for _, id := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			admins[id] = true
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := c.Get("user").(*jwt.Token)
+			if ok {
+				if claims, ok := token.Claims.(*model.TokenDetails); ok && admins[claims.UserID] {
+					return next(c)
+				}
+			}
+			return c.JSON(http.StatusForbidden, model.Response{
+				StatusCode: http.StatusForbidden,
+				Message:    "Không có quyền truy cập",
+			})
+		}
+	}
+}
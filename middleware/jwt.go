@@ -17,3 +17,16 @@ func JWTMiddleware() echo.MiddlewareFunc {
 
 	return middleware.JWTWithConfig(config)
 }
+
+// OptionalJWTMiddleware - like JWTMiddleware but lets requests without a token through as anonymous
+func OptionalJWTMiddleware() echo.MiddlewareFunc {
+	config := middleware.JWTConfig{
+		Claims:     &model.TokenDetails{},
+		SigningKey: []byte(os.Getenv("ACCESS_SECRET")),
+		Skipper: func(c echo.Context) bool {
+			return c.Request().Header.Get(echo.HeaderAuthorization) == ""
+		},
+	}
+
+	return middleware.JWTWithConfig(config)
+}
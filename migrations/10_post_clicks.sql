@@ -0,0 +1,18 @@
+-- +goose Up
+
+-- numeric id used by /go/{id}, existing posts are numbered by the sequence
+ALTER TABLE "posts"
+  ADD COLUMN "post_id" bigserial UNIQUE,
+  ADD COLUMN "clicks" bigint NOT NULL DEFAULT 0;
+
+-- clicks per day, flushed in batches from redis
+CREATE TABLE "post_click_days" (
+  "post_id" bigint NOT NULL REFERENCES "posts" ("post_id") ON DELETE CASCADE,
+  "day" date NOT NULL,
+  "clicks" bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY ("post_id", "day")
+);
+
+CREATE INDEX "post_click_days_day_idx" ON "post_click_days" ("day");
+
+ALTER TABLE "post_trending" ADD COLUMN "clicks" bigint NOT NULL DEFAULT 0;
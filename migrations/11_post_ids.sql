@@ -0,0 +1,27 @@
+-- +goose Up
+
+-- post_id becomes the primary key, link stays unique. post_tags and post_trending keep
+-- referencing the link but follow it when a post is re-slugged
+ALTER TABLE "post_tags" DROP CONSTRAINT "post_tags_post_link_fkey";
+ALTER TABLE "post_trending" DROP CONSTRAINT "post_trending_post_link_fkey";
+ALTER TABLE "post_click_days" DROP CONSTRAINT "post_click_days_post_id_fkey";
+ALTER TABLE "bookmarks" DROP CONSTRAINT "bookmarks_post_name_fkey";
+
+ALTER TABLE "posts" DROP CONSTRAINT "posts_pkey";
+ALTER TABLE "posts" DROP CONSTRAINT "posts_post_id_key";
+ALTER TABLE "posts" ADD PRIMARY KEY ("post_id");
+ALTER TABLE "posts" ADD CONSTRAINT "posts_link_key" UNIQUE ("link");
+
+ALTER TABLE "post_tags" ADD FOREIGN KEY ("post_link") REFERENCES "posts" ("link") ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE "post_trending" ADD FOREIGN KEY ("post_link") REFERENCES "posts" ("link") ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE "post_click_days" ADD FOREIGN KEY ("post_id") REFERENCES "posts" ("post_id") ON DELETE CASCADE;
+
+-- bookmarks.post_name held the link of the post
+ALTER TABLE "bookmarks" ADD COLUMN "post_id" bigint;
+UPDATE "bookmarks" SET "post_id" = "posts"."post_id" FROM "posts" WHERE "posts"."link" = "bookmarks"."post_name";
+DELETE FROM "bookmarks" WHERE "post_id" IS NULL;
+
+ALTER TABLE "bookmarks" DROP COLUMN "post_name";
+ALTER TABLE "bookmarks" ALTER COLUMN "post_id" SET NOT NULL;
+ALTER TABLE "bookmarks" ADD FOREIGN KEY ("post_id") REFERENCES "posts" ("post_id") ON DELETE CASCADE;
+ALTER TABLE "bookmarks" ADD CONSTRAINT "bookmarks_user_id_post_id_key" UNIQUE ("user_id", "post_id");
@@ -0,0 +1,14 @@
+-- +goose Up
+
+-- simhash of the title and the primary post of a group of near-duplicates,
+-- run "devread posts dedupe" once to canonicalize and group the posts already stored
+ALTER TABLE "posts"
+  ADD COLUMN "simhash" bigint,
+  ADD COLUMN "duplicate_of" bigint REFERENCES "posts" ("post_id") ON DELETE SET NULL;
+
+-- 4 bands of 16 bits, near-duplicates share at least one
+CREATE INDEX "posts_simhash_band1_idx" ON "posts" ((("simhash" >> 48) & 65535));
+CREATE INDEX "posts_simhash_band2_idx" ON "posts" ((("simhash" >> 32) & 65535));
+CREATE INDEX "posts_simhash_band3_idx" ON "posts" ((("simhash" >> 16) & 65535));
+CREATE INDEX "posts_simhash_band4_idx" ON "posts" (("simhash" & 65535));
+CREATE INDEX "posts_duplicate_of_idx" ON "posts" ("duplicate_of");
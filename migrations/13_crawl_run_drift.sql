@@ -0,0 +1,8 @@
+-- +goose Up
+
+-- posts parsed with an empty field and anomalies found against the previous runs of the source
+ALTER TABLE "crawl_runs"
+  ADD COLUMN "empty_names" integer NOT NULL DEFAULT 0,
+  ADD COLUMN "empty_links" integer NOT NULL DEFAULT 0,
+  ADD COLUMN "empty_tags" integer NOT NULL DEFAULT 0,
+  ADD COLUMN "anomalies" text[] NOT NULL DEFAULT '{}';
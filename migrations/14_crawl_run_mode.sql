@@ -0,0 +1,4 @@
+-- +goose Up
+
+-- incremental runs stop at known posts, full runs walk every page
+ALTER TABLE "crawl_runs" ADD COLUMN "mode" text NOT NULL DEFAULT 'full';
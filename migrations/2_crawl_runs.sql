@@ -0,0 +1,17 @@
+-- +goose Up
+
+CREATE TABLE "crawl_runs" (
+  "run_id" text PRIMARY KEY,
+  "source" text NOT NULL,
+  "started_at" TIMESTAMPTZ NOT NULL,
+  "finished_at" TIMESTAMPTZ NOT NULL,
+  "pages_visited" integer NOT NULL DEFAULT 0,
+  "posts_found" integer NOT NULL DEFAULT 0,
+  "posts_inserted" integer NOT NULL DEFAULT 0,
+  "posts_updated" integer NOT NULL DEFAULT 0,
+  "error_count" integer NOT NULL DEFAULT 0,
+  "last_error" text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX "crawl_runs_source_started_at_idx" ON "crawl_runs" ("source", "started_at" DESC);
+
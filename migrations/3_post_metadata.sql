@@ -0,0 +1,23 @@
+-- +goose Up
+
+ALTER TABLE "posts"
+  ADD COLUMN "source" text NOT NULL DEFAULT '',
+  ADD COLUMN "author" text NOT NULL DEFAULT '',
+  ADD COLUMN "excerpt" text NOT NULL DEFAULT '',
+  ADD COLUMN "cover_image" text NOT NULL DEFAULT '',
+  ADD COLUMN "published_at" TIMESTAMPTZ,
+  ADD COLUMN "crawled_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ADD COLUMN "updated_at" TIMESTAMPTZ NOT NULL DEFAULT now();
+
+UPDATE "posts" SET "source" = CASE
+  WHEN "link" LIKE 'https://viblo.asia/%' THEN 'viblo'
+  WHEN "link" LIKE 'https://toidicodedao.com/%' THEN 'toidicodedao'
+  WHEN "link" LIKE 'https://thefullsnack.com/%' THEN 'thefullsnack'
+  WHEN "link" LIKE 'https://quan-cam.com/%' THEN 'quancam'
+  WHEN "link" LIKE 'https://codeaholicguy.com/%' THEN 'codeaholicguy'
+  WHEN "link" LIKE 'https://yellowcodebooks.com/%' THEN 'yellowcode'
+  ELSE ''
+END;
+
+CREATE INDEX "posts_published_at_idx" ON "posts" ("published_at" DESC NULLS LAST);
+CREATE INDEX "posts_source_idx" ON "posts" ("source");
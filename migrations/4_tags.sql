@@ -0,0 +1,36 @@
+-- +goose Up
+
+CREATE TABLE "tags" (
+  "tag_id" serial PRIMARY KEY,
+  "name" text NOT NULL UNIQUE
+);
+
+CREATE TABLE "post_tags" (
+  "post_link" text NOT NULL,
+  "tag_id" integer NOT NULL,
+  PRIMARY KEY ("post_link", "tag_id")
+);
+
+ALTER TABLE "post_tags" ADD FOREIGN KEY ("post_link") REFERENCES "posts" ("link") ON DELETE CASCADE;
+ALTER TABLE "post_tags" ADD FOREIGN KEY ("tag_id") REFERENCES "tags" ("tag_id") ON DELETE CASCADE;
+
+CREATE INDEX "post_tags_tag_id_idx" ON "post_tags" ("tag_id");
+
+-- quan-cam stored every hashtag in one space separated string
+CREATE TEMPORARY TABLE "split_tags" AS
+  SELECT "link", regexp_split_to_table(trim("tag"), '\s+') AS "name" FROM "posts" WHERE "source" = 'quancam'
+  UNION
+  SELECT "link", trim("tag") FROM "posts" WHERE "source" <> 'quancam';
+
+DELETE FROM "split_tags" WHERE "name" IS NULL OR "name" = '';
+
+INSERT INTO "tags" ("name")
+  SELECT DISTINCT "name" FROM "split_tags"
+  ON CONFLICT ("name") DO NOTHING;
+
+INSERT INTO "post_tags" ("post_link", "tag_id")
+  SELECT DISTINCT "split_tags"."link", "tags"."tag_id"
+  FROM "split_tags"
+  INNER JOIN "tags" ON "tags"."name" = "split_tags"."name";
+
+DROP TABLE "split_tags";
@@ -0,0 +1,32 @@
+-- +goose Up
+
+CREATE TABLE "tag_aliases" (
+  "alias" text PRIMARY KEY,
+  "tag" text NOT NULL,
+  "created_at" TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO "tag_aliases" ("alias", "tag") VALUES
+  ('golang', 'go'),
+  ('go-lang', 'go'),
+  ('js', 'javascript'),
+  ('java-script', 'javascript'),
+  ('ts', 'typescript'),
+  ('reactjs', 'react'),
+  ('react.js', 'react'),
+  ('react-js', 'react'),
+  ('node.js', 'nodejs'),
+  ('node-js', 'nodejs'),
+  ('vuejs', 'vue'),
+  ('vue.js', 'vue'),
+  ('py', 'python'),
+  ('k8s', 'kubernetes'),
+  ('postgres', 'postgresql'),
+  ('ml', 'machine-learning'),
+  ('hoc-may', 'machine-learning'),
+  ('lap-trinh', 'programming'),
+  ('lap-trinh-android', 'android'),
+  ('lap-trinh-java', 'java'),
+  ('co-so-du-lieu', 'database'),
+  ('thuat-toan', 'algorithm'),
+  ('bao-mat', 'security');
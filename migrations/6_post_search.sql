@@ -0,0 +1,14 @@
+-- +goose Up
+
+ALTER TABLE "posts" ADD COLUMN "search_vector" tsvector;
+
+UPDATE "posts" SET "search_vector" =
+  setweight(to_tsvector('simple', COALESCE("name", '')), 'A') ||
+  setweight(to_tsvector('simple', COALESCE((
+    SELECT string_agg("tags"."name", ' ')
+    FROM "post_tags"
+    INNER JOIN "tags" ON "tags"."tag_id" = "post_tags"."tag_id"
+    WHERE "post_tags"."post_link" = "posts"."link"), '')), 'B') ||
+  setweight(to_tsvector('simple', COALESCE("excerpt", '')), 'C');
+
+CREATE INDEX "posts_search_vector_idx" ON "posts" USING GIN ("search_vector");
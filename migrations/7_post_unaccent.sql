@@ -0,0 +1,22 @@
+-- +goose Up
+
+-- folded (lowercase, no diacritics) copies of name and excerpt, written by the crawler
+-- so "su dung" matches "sử dụng"; unaccent is only needed to backfill existing rows
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+ALTER TABLE "posts"
+  ADD COLUMN "name_unaccent" text NOT NULL DEFAULT '',
+  ADD COLUMN "excerpt_unaccent" text NOT NULL DEFAULT '';
+
+UPDATE "posts" SET
+  "name_unaccent" = lower(unaccent(COALESCE("name", ''))),
+  "excerpt_unaccent" = lower(unaccent("excerpt"));
+
+UPDATE "posts" SET "search_vector" =
+  setweight(to_tsvector('simple', "name_unaccent"), 'A') ||
+  setweight(to_tsvector('simple', COALESCE((
+    SELECT string_agg("tags"."name", ' ')
+    FROM "post_tags"
+    INNER JOIN "tags" ON "tags"."tag_id" = "post_tags"."tag_id"
+    WHERE "post_tags"."post_link" = "posts"."link"), '')), 'B') ||
+  setweight(to_tsvector('simple', "excerpt_unaccent"), 'C');
@@ -0,0 +1,6 @@
+-- +goose Up
+
+-- keyset pagination of /trend and /posts, see repo_impl/post_page.go
+CREATE INDEX "posts_newest_idx" ON "posts" ((COALESCE("published_at", "crawled_at")) DESC, "link" DESC);
+CREATE INDEX "posts_title_idx" ON "posts" ((COALESCE("name", '')), "link");
+CREATE INDEX "posts_source_newest_idx" ON "posts" ("source", (COALESCE("published_at", "crawled_at")) DESC, "link" DESC);
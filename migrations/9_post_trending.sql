@@ -0,0 +1,18 @@
+-- +goose Up
+
+-- last time the post was listed on its source's own trending page (viblo /trending)
+ALTER TABLE "posts" ADD COLUMN "source_trending_at" TIMESTAMPTZ;
+
+-- trending score per window, recomputed periodically by the crawl worker
+CREATE TABLE "post_trending" (
+  "period" text NOT NULL,
+  "post_link" text NOT NULL REFERENCES "posts" ("link") ON DELETE CASCADE,
+  "score" double precision NOT NULL,
+  "bookmarks" integer NOT NULL DEFAULT 0,
+  "source_trending" boolean NOT NULL DEFAULT false,
+  "computed_at" TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY ("period", "post_link")
+);
+
+CREATE INDEX "post_trending_period_score_idx" ON "post_trending" ("period", "score" DESC, "post_link" DESC);
+CREATE INDEX "bookmarks_created_at_idx" ON "bookmarks" ("created_at");
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type CrawlRun struct {
+	RunID         string    `json:"run_id" db:"run_id, omitempty"`
+	Source        string    `json:"source" db:"source, omitempty"`
+	Mode          string    `json:"mode" db:"mode, omitempty"`
+	StartedAt     time.Time `json:"started_at" db:"started_at, omitempty"`
+	FinishedAt    time.Time `json:"finished_at" db:"finished_at, omitempty"`
+	PagesVisited  int       `json:"pages_visited" db:"pages_visited, omitempty"`
+	PostsFound    int       `json:"posts_found" db:"posts_found, omitempty"`
+	PostsInserted int       `json:"posts_inserted" db:"posts_inserted, omitempty"`
+	PostsUpdated  int       `json:"posts_updated" db:"posts_updated, omitempty"`
+	ErrorCount    int       `json:"error_count" db:"error_count, omitempty"`
+	LastError     string    `json:"last_error" db:"last_error, omitempty"`
+
+	// posts parsed without name, link or tag, a rising count means a selector broke
+	EmptyNames int `json:"empty_names" db:"empty_names, omitempty"`
+	EmptyLinks int `json:"empty_links" db:"empty_links, omitempty"`
+	EmptyTags  int `json:"empty_tags" db:"empty_tags, omitempty"`
+	// Anomalies - differences with the previous runs of the source, see crawler/drift.go
+	Anomalies pq.StringArray `json:"anomalies" db:"anomalies, omitempty"`
+}
+
+type SourceHealth struct {
+	Source         string         `json:"source" db:"source, omitempty"`
+	Status         string         `json:"status"`
+	LastMode       string         `json:"last_mode" db:"last_mode, omitempty"`
+	LastRunAt      time.Time      `json:"last_run_at" db:"last_run_at, omitempty"`
+	LastPostsFound int            `json:"last_posts_found" db:"last_posts_found, omitempty"`
+	LastErrorCount int            `json:"last_error_count" db:"last_error_count, omitempty"`
+	LastError      string         `json:"last_error" db:"last_error, omitempty"`
+	AvgPostsFound  float64        `json:"avg_posts_found" db:"avg_posts_found, omitempty"`
+	LastAnomalies  pq.StringArray `json:"last_anomalies" db:"last_anomalies, omitempty"`
+}
@@ -0,0 +1,22 @@
+package model
+
+const (
+	// SortTrending - by trending score, only for /trend
+	SortTrending = "trending"
+	SortNewest   = "newest"
+	SortTitle    = "title"
+	SortSource   = "source"
+)
+
+// PageQuery - one page of a keyset paginated listing
+type PageQuery struct {
+	Limit  int
+	Cursor string
+	Sort   string
+}
+
+// Page - posts of one page and the cursor of the next one, empty on the last page
+type Page struct {
+	Posts      []Post
+	NextCursor string
+}
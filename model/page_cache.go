@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// CachedPage - a crawled page kept to revalidate it with a conditional GET
+type CachedPage struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	Body         []byte    `json:"body"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
@@ -1,8 +1,44 @@
 package model
 
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
 type Post struct {
-	Name       string `json:"name" db:"name,omitempty"`
-	Link       string `json:"link" db:"link,omitempty"`
-	Tag        string `json:"tag" db:"tag,omitempty"`
-	Bookmarked bool   `json:"bookmarked"`
+	ID          int64          `json:"id" db:"post_id,omitempty"`
+	Name        string         `json:"name" db:"name,omitempty"`
+	Link        string         `json:"link" db:"link,omitempty"`
+	Tag         string         `json:"tag" db:"tag,omitempty"`
+	Tags        pq.StringArray `json:"tags" db:"tags,omitempty"`
+	Source      string         `json:"source" db:"source,omitempty"`
+	Author      string         `json:"author" db:"author,omitempty"`
+	Excerpt     string         `json:"excerpt" db:"excerpt,omitempty"`
+	CoverImage  string         `json:"cover_image" db:"cover_image,omitempty"`
+	PublishedAt *time.Time     `json:"published_at" db:"published_at,omitempty"`
+	CrawledAt   time.Time      `json:"crawled_at" db:"crawled_at,omitempty"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at,omitempty"`
+	Clicks      int64          `json:"clicks" db:"clicks,omitempty"`
+	Bookmarked  bool           `json:"bookmarked"`
+	// DuplicateOf - id of the primary post when this one is a cross-post or a near-duplicate
+	DuplicateOf *int64 `json:"duplicate_of,omitempty" db:"duplicate_of,omitempty"`
+
+	// TrendingScore - score in the requested /trend window
+	TrendingScore float64 `json:"trending_score,omitempty" db:"trending_score,omitempty"`
+	// SourceTrending - the crawler found the post on its source's trending page
+	SourceTrending bool `json:"-" db:"-"`
+
+	// folded copies for diacritic-insensitive search, filled by the repository
+	NameUnaccent    string `json:"-" db:"name_unaccent,omitempty"`
+	ExcerptUnaccent string `json:"-" db:"excerpt_unaccent,omitempty"`
+	// Simhash - fingerprint of the name used to find near-duplicates, filled by the repository
+	Simhash int64 `json:"-" db:"simhash,omitempty"`
+}
+
+// UpsertResult - posts inserted and number of posts updated by an upsert,
+// posts already stored and unchanged are in neither
+type UpsertResult struct {
+	Inserted []Post
+	Updated  int
 }
@@ -1,5 +1,5 @@
 package req
 
 type ReqBookmark struct {
-	PostName string `json:"post,omitempty" validate:"required"`
+	PostID int64 `json:"post_id,omitempty" validate:"required"`
 }
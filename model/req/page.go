@@ -0,0 +1,7 @@
+package req
+
+type ReqPage struct {
+	Limit  int    `query:"limit"`
+	Cursor string `query:"cursor"`
+	Sort   string `query:"sort"`
+}
@@ -0,0 +1,10 @@
+package req
+
+type ReqSearch struct {
+	Query  string `query:"q" validate:"required"`
+	Source string `query:"source"`
+	Tag    string `query:"tag"`
+	From   string `query:"from"`
+	To     string `query:"to"`
+	Limit  int    `query:"limit"`
+}
@@ -0,0 +1,6 @@
+package req
+
+type ReqTagAlias struct {
+	Alias string `json:"alias,omitempty" validate:"required"`
+	Tag   string `json:"tag,omitempty" validate:"required"`
+}
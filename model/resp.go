@@ -4,4 +4,5 @@ type Response struct {
 	StatusCode int         `json:"status,omitempty"`
 	Message    string      `json:"message,omitempty"`
 	Data       interface{} `json:"data,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"`
 }
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+type SearchQuery struct {
+	Query  string
+	Source string
+	Tag    string
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+}
+
+type SearchResult struct {
+	Post
+	Rank             float64 `json:"rank" db:"rank"`
+	NameHighlight    string  `json:"name_highlight" db:"-"`
+	ExcerptHighlight string  `json:"excerpt_highlight" db:"-"`
+}
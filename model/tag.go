@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+type TagAlias struct {
+	Alias     string    `json:"alias" db:"alias, omitempty"`
+	Tag       string    `json:"tag" db:"tag, omitempty"`
+	CreatedAt time.Time `json:"created_at" db:"created_at, omitempty"`
+}
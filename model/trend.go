@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// TrendWindow - period the trending score of /trend is computed over
+type TrendWindow struct {
+	Name     string
+	Duration time.Duration
+}
+
+const DefaultTrendWindow = "7d"
+
+var TrendWindows = []TrendWindow{
+	{Name: "24h", Duration: 24 * time.Hour},
+	{Name: "7d", Duration: 7 * 24 * time.Hour},
+	{Name: "30d", Duration: 30 * 24 * time.Hour},
+}
+
+func LookupTrendWindow(name string) (TrendWindow, bool) {
+	for _, window := range TrendWindows {
+		if window.Name == name {
+			return window, true
+		}
+	}
+	return TrendWindow{}, false
+}
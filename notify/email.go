@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// Email - sends the alert with the SMTP account used for account emails
+type Email struct {
+	Host     string
+	Port     string
+	From     string
+	Password string
+	To       []string
+}
+
+// EmailFromEnv - SMTP_HOST, SMTP_PORT, FROM and PASSWORD like the user emails,
+// recipients in ALERT_EMAIL_TO separated by commas
+func EmailFromEnv() Email {
+	to := []string{}
+	for _, address := range strings.Split(os.Getenv("ALERT_EMAIL_TO"), ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			to = append(to, address)
+		}
+	}
+	return Email{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		From:     os.Getenv("FROM"),
+		Password: os.Getenv("PASSWORD"),
+		To:       to,
+	}
+}
+
+func (e Email) Notify(ctx context.Context, alert Alert) error {
+	if len(e.To) == 0 {
+		return errors.New("thiếu ALERT_EMAIL_TO")
+	}
+
+	subject := alert.Subject() + "\r\n"
+	mime := "MIME-version: 1.0;\nContent-Type: text/plain; charset=\"UTF-8\";\n\n"
+	message := []byte("Subject:" + subject + mime + "\r\n" + alert.Text())
+
+	auth := smtp.PlainAuth("", e.From, e.Password, e.Host)
+	return smtp.SendMail(e.Host+":"+e.Port, auth, e.From, e.To, message)
+}
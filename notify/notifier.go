@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Alert - anomalies found in a crawl run
+type Alert struct {
+	Source    string    `json:"source"`
+	RunID     string    `json:"run_id"`
+	StartedAt time.Time `json:"started_at"`
+	Anomalies []string  `json:"anomalies"`
+}
+
+// Subject - one line summary of the alert
+func (a Alert) Subject() string {
+	return fmt.Sprintf("[DevRead] Crawler %s có dấu hiệu lỗi selector", a.Source)
+}
+
+// Text - plain text body of the alert
+func (a Alert) Text() string {
+	var b strings.Builder
+	b.WriteString(a.Subject())
+	fmt.Fprintf(&b, "\nLượt crawl %s lúc %s:", a.RunID, a.StartedAt.Format(time.RFC3339))
+	for _, anomaly := range a.Anomalies {
+		b.WriteString("\n- " + anomaly)
+	}
+	return b.String()
+}
+
+// Notifier - sends crawl alerts somewhere a maintainer will see them
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// Multi - sends the alert through every notifier, even when one of them fails
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, alert Alert) error {
+	failed := []string{}
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, alert); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("gửi cảnh báo thất bại: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// FromEnv - notifiers listed in ALERT_NOTIFIERS (log, webhook, email separated by commas), log by default
+func FromEnv(logger *zap.Logger) Notifier {
+	names := os.Getenv("ALERT_NOTIFIERS")
+	if names == "" {
+		names = "log"
+	}
+
+	notifiers := Multi{}
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "log":
+			notifiers = append(notifiers, Log{Logger: logger})
+		case "webhook":
+			notifiers = append(notifiers, NewWebhook(os.Getenv("ALERT_WEBHOOK_URL")))
+		case "email":
+			notifiers = append(notifiers, EmailFromEnv())
+		case "":
+		default:
+			logger.Warn("Bỏ qua kênh cảnh báo không hỗ trợ ", zap.String("notifier", name))
+		}
+	}
+	return notifiers
+}
+
+// Log - writes the alert to the application log
+type Log struct {
+	Logger *zap.Logger
+}
+
+func (l Log) Notify(ctx context.Context, alert Alert) error {
+	l.Logger.Warn("Cảnh báo crawler ",
+		zap.String("source", alert.Source),
+		zap.String("run_id", alert.RunID),
+		zap.Strings("anomalies", alert.Anomalies))
+	return nil
+}
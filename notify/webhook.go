@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Webhook - posts the alert as JSON, the "text" field is understood by Slack, Discord (via /slack) and Mattermost
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhook(url string) Webhook {
+	return Webhook{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type webhookBody struct {
+	Text string `json:"text"`
+	Alert
+}
+
+func (w Webhook) Notify(ctx context.Context, alert Alert) error {
+	if w.URL == "" {
+		return errors.New("thiếu ALERT_WEBHOOK_URL")
+	}
+
+	body, err := json.Marshal(webhookBody{Text: alert.Text(), Alert: alert})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook trả về %s", resp.Status)
+	}
+	return nil
+}
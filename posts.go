@@ -0,0 +1,32 @@
+package main
+
+import (
+	"devread/dedupe"
+	"devread/repository/repo_impl"
+
+	"context"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// posts - maintenance of stored posts, "dedupe" canonicalizes their links and groups duplicates
+func posts(ctx context.Context, log *zap.Logger, args []string) int {
+	if len(args) != 1 || args[0] != "dedupe" {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	sql := connectPostgres(log)
+	defer sql.Close()
+
+	detector := dedupe.NewDetector(repo_impl.NewPostRepo(sql), log)
+	canonicalized, grouped, err := detector.Backfill(ctx)
+	fmt.Printf("Đã chuẩn hoá %d link, gộp %d bài viết trùng\n", canonicalized, grouped)
+	if err != nil {
+		log.Error("Gộp bài viết trùng thất bại ", zap.Error(err))
+		return 1
+	}
+	return 0
+}
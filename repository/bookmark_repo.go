@@ -8,6 +8,6 @@ import (
 
 type BookmarkRepo interface {
 	SelectAll(context context.Context, userId string) ([]model.Post, error)
-	Bookmark(context context.Context, bid, namePost, userId string) error
-	Delete(context context.Context, namePost, userId string) error
+	Bookmark(context context.Context, bid string, postID int64, userId string) error
+	Delete(context context.Context, postID int64, userId string) error
 }
@@ -0,0 +1,8 @@
+package repository
+
+import "context"
+
+type ClickRepo interface {
+	Record(postID int64, visitor string) (bool, error)
+	Flush(context context.Context) (int, error)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"devread/model"
+)
+
+type CrawlRunRepo interface {
+	Save(context context.Context, run model.CrawlRun) (model.CrawlRun, error)
+	SelectAll(context context.Context, source string, limit int) ([]model.CrawlRun, error)
+	SelectHealth(context context.Context) ([]model.SourceHealth, error)
+}
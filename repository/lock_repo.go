@@ -0,0 +1,9 @@
+package repository
+
+import "time"
+
+type LockRepo interface {
+	Acquire(key, owner string, ttl time.Duration) (bool, error)
+	Renew(key, owner string, ttl time.Duration) (bool, error)
+	Release(key, owner string) error
+}
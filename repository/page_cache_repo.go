@@ -0,0 +1,9 @@
+package repository
+
+import "devread/model"
+
+type PageCacheRepo interface {
+	// Get - cached page of the url, false when there is none
+	Get(url string) (model.CachedPage, bool, error)
+	Save(page model.CachedPage) error
+}
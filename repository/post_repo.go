@@ -7,9 +7,18 @@ import (
 )
 
 type PostRepo interface {
-	Update(context context.Context, post model.Post) (model.Post, error)
-	Save(context context.Context, post model.Post) (model.Post, error)
-	SelectAll(context context.Context) ([]model.Post, error)
-	SelectByTag(context context.Context, tag string) ([]model.Post, error)
+	Upsert(context context.Context, post model.Post) (model.UpsertResult, error)
+	UpsertMany(context context.Context, posts []model.Post) (model.UpsertResult, error)
+	SelectAll(context context.Context, page model.PageQuery) (model.Page, error)
+	SelectByTag(context context.Context, tag string, page model.PageQuery) (model.Page, error)
+	SelectByID(context context.Context, id int64) (model.Post, error)
 	SelectByLink(context context.Context, link string) (model.Post, error)
+	// SelectKnownLinks - the links among links already stored
+	SelectKnownLinks(context context.Context, links []string) (map[string]bool, error)
+	SelectSimilar(context context.Context, link string, simhash int64) ([]model.Post, error)
+	SelectDedupe(context context.Context) ([]model.Post, error)
+	MarkDuplicate(context context.Context, link string, primaryID int64) error
+	UpdateLink(context context.Context, from, to string) error
+	SaveSimhash(context context.Context, link string, simhash int64) error
+	Search(context context.Context, query model.SearchQuery) ([]model.SearchResult, error)
 }
@@ -0,0 +1,51 @@
+package repo_fake
+
+import (
+	"context"
+	"sync"
+)
+
+// Click - a click recorded by ClickRepoFake
+type Click struct {
+	PostID  int64
+	Visitor string
+}
+
+// ClickRepoFake - repository.ClickRepo in memory, a visitor counts once per post
+type ClickRepoFake struct {
+	mu      sync.Mutex
+	clicks  []Click
+	pending int
+}
+
+func NewClickRepo() *ClickRepoFake {
+	return &ClickRepoFake{}
+}
+
+// Clicks - every recorded click, oldest first
+func (cr *ClickRepoFake) Clicks() []Click {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	return append([]Click{}, cr.clicks...)
+}
+
+func (cr *ClickRepoFake) Record(postID int64, visitor string) (bool, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	for _, click := range cr.clicks {
+		if click.PostID == postID && click.Visitor == visitor {
+			return false, nil
+		}
+	}
+	cr.clicks = append(cr.clicks, Click{PostID: postID, Visitor: visitor})
+	cr.pending++
+	return true, nil
+}
+
+func (cr *ClickRepoFake) Flush(context context.Context) (int, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	flushed := cr.pending
+	cr.pending = 0
+	return flushed, nil
+}
@@ -0,0 +1,72 @@
+package repo_fake
+
+import (
+	"context"
+	"sync"
+
+	"devread/model"
+)
+
+// CrawlRunRepoFake - repository.CrawlRunRepo kept in memory
+type CrawlRunRepoFake struct {
+	mu   sync.Mutex
+	runs []model.CrawlRun
+}
+
+func NewCrawlRunRepo() *CrawlRunRepoFake {
+	return &CrawlRunRepoFake{}
+}
+
+func (cr *CrawlRunRepoFake) Save(context context.Context, run model.CrawlRun) (model.CrawlRun, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.runs = append(cr.runs, run)
+	return run, nil
+}
+
+// SelectAll - newest runs first
+func (cr *CrawlRunRepoFake) SelectAll(context context.Context, source string, limit int) ([]model.CrawlRun, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	runs := []model.CrawlRun{}
+	for i := len(cr.runs) - 1; i >= 0 && len(runs) < limit; i-- {
+		if source == "" || cr.runs[i].Source == source {
+			runs = append(runs, cr.runs[i])
+		}
+	}
+	return runs, nil
+}
+
+// SelectHealth - last run of each source, the average is over its last 10 runs in the same mode
+func (cr *CrawlRunRepoFake) SelectHealth(context context.Context) ([]model.SourceHealth, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	health := []model.SourceHealth{}
+	index := map[string]int{}
+	counts := map[string]int{}
+	for i := len(cr.runs) - 1; i >= 0; i-- {
+		run := cr.runs[i]
+		j, ok := index[run.Source]
+		if !ok {
+			j = len(health)
+			index[run.Source] = j
+			health = append(health, model.SourceHealth{
+				Source:         run.Source,
+				LastMode:       run.Mode,
+				LastRunAt:      run.StartedAt,
+				LastPostsFound: run.PostsFound,
+				LastErrorCount: run.ErrorCount,
+				LastError:      run.LastError,
+				LastAnomalies:  run.Anomalies,
+			})
+		}
+		if run.Mode == health[j].LastMode && counts[run.Source] < 10 {
+			n := float64(counts[run.Source])
+			health[j].AvgPostsFound = (health[j].AvgPostsFound*n + float64(run.PostsFound)) / (n + 1)
+			counts[run.Source]++
+		}
+	}
+	return health, nil
+}
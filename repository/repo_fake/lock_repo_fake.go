@@ -0,0 +1,62 @@
+package repo_fake
+
+import (
+	"sync"
+	"time"
+)
+
+type lock struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// LockRepoFake - repository.LockRepo in memory
+type LockRepoFake struct {
+	mu    sync.Mutex
+	locks map[string]lock
+}
+
+func NewLockRepo() *LockRepoFake {
+	return &LockRepoFake{
+		locks: map[string]lock{},
+	}
+}
+
+// held - the lock of key if it has not expired, mu must be held
+func (l *LockRepoFake) held(key string) (lock, bool) {
+	current, ok := l.locks[key]
+	if !ok || time.Now().After(current.expiresAt) {
+		return lock{}, false
+	}
+	return current, true
+}
+
+func (l *LockRepoFake) Acquire(key, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.held(key); ok {
+		return false, nil
+	}
+	l.locks[key] = lock{owner: owner, expiresAt: time.Now().Add(ttl)}
+	return true, nil
+}
+
+func (l *LockRepoFake) Renew(key, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	current, ok := l.held(key)
+	if !ok || current.owner != owner {
+		return false, nil
+	}
+	l.locks[key] = lock{owner: owner, expiresAt: time.Now().Add(ttl)}
+	return true, nil
+}
+
+func (l *LockRepoFake) Release(key, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if current, ok := l.held(key); ok && current.owner == owner {
+		delete(l.locks, key)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package repo_fake
+
+import (
+	"sync"
+
+	"devread/model"
+)
+
+// PageCacheRepoFake - repository.PageCacheRepo in memory
+type PageCacheRepoFake struct {
+	mu    sync.Mutex
+	pages map[string]model.CachedPage
+}
+
+func NewPageCacheRepo() *PageCacheRepoFake {
+	return &PageCacheRepoFake{
+		pages: map[string]model.CachedPage{},
+	}
+}
+
+func (p *PageCacheRepoFake) Get(url string) (model.CachedPage, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	page, ok := p.pages[url]
+	return page, ok, nil
+}
+
+func (p *PageCacheRepoFake) Save(page model.CachedPage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pages[page.URL] = page
+	return nil
+}
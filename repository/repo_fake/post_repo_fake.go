@@ -0,0 +1,369 @@
+// Package repo_fake holds in-memory repositories for tests
+package repo_fake
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"devread/custom_error"
+	"devread/helper"
+	"devread/model"
+)
+
+// PostRepoFake - repository.PostRepo kept in memory, safe for concurrent use
+type PostRepoFake struct {
+	mu     sync.Mutex
+	nextID int64
+	posts  []model.Post
+}
+
+func NewPostRepo() *PostRepoFake {
+	return &PostRepoFake{}
+}
+
+// Posts - copy of every stored post, oldest first
+func (p *PostRepoFake) Posts() []model.Post {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]model.Post{}, p.posts...)
+}
+
+func (p *PostRepoFake) Upsert(context context.Context, post model.Post) (model.UpsertResult, error) {
+	return p.UpsertMany(context, []model.Post{post})
+}
+
+// UpsertMany - same rules as the SQL upsert: empty metadata never overwrites stored metadata,
+// unchanged posts are neither inserted nor updated
+func (p *PostRepoFake) UpsertMany(context context.Context, posts []model.Post) (model.UpsertResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := model.UpsertResult{}
+	seen := map[string]bool{}
+	for _, post := range posts {
+		if seen[post.Link] {
+			continue
+		}
+		seen[post.Link] = true
+
+		if len(post.Tags) > 0 {
+			post.Tags = append(post.Tags[:0:0], post.Tags...)
+			sort.Strings(post.Tags)
+		}
+		post.Simhash = int64(helper.Simhash(post.Name))
+
+		i := p.indexByLink(post.Link)
+		if i < 0 {
+			p.nextID++
+			post.ID = p.nextID
+			post.CrawledAt = time.Now()
+			post.UpdatedAt = post.CrawledAt
+			p.posts = append(p.posts, post)
+			result.Inserted = append(result.Inserted, post)
+			continue
+		}
+
+		stored := &p.posts[i]
+		if len(post.Tags) > 0 {
+			stored.Tags = post.Tags
+		}
+		if !changed(*stored, post) {
+			continue
+		}
+		stored.Name = post.Name
+		stored.Simhash = post.Simhash
+		if post.Tag != "" {
+			stored.Tag = post.Tag
+		}
+		if post.Author != "" {
+			stored.Author = post.Author
+		}
+		if post.Excerpt != "" {
+			stored.Excerpt = post.Excerpt
+		}
+		if post.CoverImage != "" {
+			stored.CoverImage = post.CoverImage
+		}
+		if post.PublishedAt != nil {
+			stored.PublishedAt = post.PublishedAt
+		}
+		stored.UpdatedAt = time.Now()
+		result.Updated++
+	}
+	return result, nil
+}
+
+func changed(stored, crawled model.Post) bool {
+	if crawled.Name != stored.Name {
+		return true
+	}
+	if crawled.Tag != "" && crawled.Tag != stored.Tag {
+		return true
+	}
+	if crawled.Author != "" && crawled.Author != stored.Author {
+		return true
+	}
+	if crawled.Excerpt != "" && crawled.Excerpt != stored.Excerpt {
+		return true
+	}
+	if crawled.CoverImage != "" && crawled.CoverImage != stored.CoverImage {
+		return true
+	}
+	return crawled.PublishedAt != nil && (stored.PublishedAt == nil || !crawled.PublishedAt.Equal(*stored.PublishedAt))
+}
+
+func (p *PostRepoFake) SelectAll(context context.Context, page model.PageQuery) (model.Page, error) {
+	return p.selectPage(page, func(post model.Post) bool { return true })
+}
+
+func (p *PostRepoFake) SelectByTag(context context.Context, tag string, page model.PageQuery) (model.Page, error) {
+	return p.selectPage(page, func(post model.Post) bool { return hasTag(post, tag) })
+}
+
+// selectPage - the cursor of the fake is the offset of the next page
+func (p *PostRepoFake) selectPage(page model.PageQuery, keep func(model.Post) bool) (model.Page, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	posts := []model.Post{}
+	for _, post := range p.posts {
+		if post.DuplicateOf == nil && keep(post) {
+			posts = append(posts, post)
+		}
+	}
+
+	switch page.Sort {
+	case model.SortNewest:
+		sort.SliceStable(posts, func(i, j int) bool {
+			di, dj := date(posts[i]), date(posts[j])
+			if !di.Equal(dj) {
+				return di.After(dj)
+			}
+			return posts[i].Link > posts[j].Link
+		})
+	case model.SortTitle:
+		sort.SliceStable(posts, func(i, j int) bool {
+			if posts[i].Name != posts[j].Name {
+				return posts[i].Name < posts[j].Name
+			}
+			return posts[i].Link < posts[j].Link
+		})
+	case model.SortSource:
+		sort.SliceStable(posts, func(i, j int) bool {
+			if posts[i].Source != posts[j].Source {
+				return posts[i].Source < posts[j].Source
+			}
+			return date(posts[i]).After(date(posts[j]))
+		})
+	default:
+		return model.Page{}, custom_error.InvalidSort
+	}
+
+	offset := 0
+	if page.Cursor != "" {
+		n, err := strconv.Atoi(page.Cursor)
+		if err != nil || n < 0 || n > len(posts) {
+			return model.Page{}, custom_error.InvalidCursor
+		}
+		offset = n
+	}
+	end := offset + page.Limit
+	if end >= len(posts) {
+		return model.Page{Posts: posts[offset:]}, nil
+	}
+	return model.Page{Posts: posts[offset:end], NextCursor: strconv.Itoa(end)}, nil
+}
+
+func (p *PostRepoFake) SelectByID(context context.Context, id int64) (model.Post, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, post := range p.posts {
+		if post.ID == id {
+			return post, nil
+		}
+	}
+	return model.Post{}, custom_error.PostNotFound
+}
+
+func (p *PostRepoFake) SelectByLink(context context.Context, link string) (model.Post, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if i := p.indexByLink(link); i >= 0 {
+		return p.posts[i], nil
+	}
+	return model.Post{}, custom_error.PostNotFound
+}
+
+func (p *PostRepoFake) SelectKnownLinks(context context.Context, links []string) (map[string]bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	known := map[string]bool{}
+	for _, link := range links {
+		if p.indexByLink(link) >= 0 {
+			known[link] = true
+		}
+	}
+	return known, nil
+}
+
+func (p *PostRepoFake) SelectSimilar(context context.Context, link string, simhash int64) ([]model.Post, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	bands := helper.SimhashBands(uint64(simhash))
+	posts := []model.Post{}
+	for _, post := range p.posts {
+		if post.Link == link || post.DuplicateOf != nil || post.Simhash == 0 {
+			continue
+		}
+		candidate := helper.SimhashBands(uint64(post.Simhash))
+		for i := range bands {
+			if bands[i] == candidate[i] {
+				posts = append(posts, post)
+				break
+			}
+		}
+	}
+	return posts, nil
+}
+
+func (p *PostRepoFake) SelectDedupe(context context.Context) ([]model.Post, error) {
+	return p.Posts(), nil
+}
+
+func (p *PostRepoFake) MarkDuplicate(context context.Context, link string, primaryID int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	i := p.indexByLink(link)
+	if i < 0 {
+		return nil
+	}
+	id := p.posts[i].ID
+	for j := range p.posts {
+		post := &p.posts[j]
+		if post.ID == primaryID {
+			continue
+		}
+		if post.ID == id || (post.DuplicateOf != nil && *post.DuplicateOf == id) {
+			primary := primaryID
+			post.DuplicateOf = &primary
+		}
+	}
+	return nil
+}
+
+func (p *PostRepoFake) UpdateLink(context context.Context, from, to string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.indexByLink(to) >= 0 {
+		return custom_error.PostConflict
+	}
+	if i := p.indexByLink(from); i >= 0 {
+		p.posts[i].Link = to
+	}
+	return nil
+}
+
+func (p *PostRepoFake) SaveSimhash(context context.Context, link string, simhash int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if i := p.indexByLink(link); i >= 0 {
+		p.posts[i].Simhash = simhash
+	}
+	return nil
+}
+
+// Search - every term must start a word of the name, tags or excerpt; rank is the number of
+// terms found in the name
+func (p *PostRepoFake) Search(context context.Context, query model.SearchQuery) ([]model.SearchResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	terms := helper.SearchTerms(query.Query)
+	results := []model.SearchResult{}
+	for _, post := range p.posts {
+		if post.DuplicateOf != nil || len(terms) == 0 {
+			continue
+		}
+		if query.Source != "" && post.Source != query.Source {
+			continue
+		}
+		if query.Tag != "" && !hasTag(post, query.Tag) {
+			continue
+		}
+		if query.From != nil && (post.PublishedAt == nil || post.PublishedAt.Before(*query.From)) {
+			continue
+		}
+		if query.To != nil && (post.PublishedAt == nil || !post.PublishedAt.Before(*query.To)) {
+			continue
+		}
+
+		name := strings.Fields(helper.Fold(post.Name))
+		text := strings.Fields(helper.Fold(post.Name + " " + strings.Join(post.Tags, " ") + " " + post.Excerpt))
+		rank, found := 0.0, true
+		for _, term := range terms {
+			if hasPrefix(name, term) {
+				rank++
+			}
+			if !hasPrefix(text, term) {
+				found = false
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		results = append(results, model.SearchResult{
+			Post:             post,
+			Rank:             rank,
+			NameHighlight:    helper.Highlight(post.Name, terms),
+			ExcerptHighlight: helper.Highlight(post.Excerpt, terms),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Rank > results[j].Rank })
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+	return results, nil
+}
+
+func (p *PostRepoFake) indexByLink(link string) int {
+	for i, post := range p.posts {
+		if post.Link == link {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasTag(post model.Post, tag string) bool {
+	for _, t := range post.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPrefix(words []string, term string) bool {
+	for _, word := range words {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func date(post model.Post) time.Time {
+	if post.PublishedAt != nil {
+		return *post.PublishedAt
+	}
+	return post.CrawledAt
+}
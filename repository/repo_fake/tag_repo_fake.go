@@ -0,0 +1,69 @@
+package repo_fake
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"devread/custom_error"
+	"devread/model"
+)
+
+// TagRepoFake - repository.TagRepo holding only aliases; the tags of posts live in PostRepoFake
+type TagRepoFake struct {
+	mu      sync.Mutex
+	aliases map[string]string
+}
+
+func NewTagRepo() *TagRepoFake {
+	return &TagRepoFake{
+		aliases: map[string]string{},
+	}
+}
+
+func (t *TagRepoFake) SelectAliases(context context.Context) ([]model.TagAlias, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	aliases := []model.TagAlias{}
+	for alias, tag := range t.aliases {
+		aliases = append(aliases, model.TagAlias{Alias: alias, Tag: tag})
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Alias < aliases[j].Alias })
+	return aliases, nil
+}
+
+func (t *TagRepoFake) SaveAlias(context context.Context, alias model.TagAlias) (model.TagAlias, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	alias.CreatedAt = time.Now()
+	t.aliases[alias.Alias] = alias.Tag
+	return alias, nil
+}
+
+func (t *TagRepoFake) DeleteAlias(context context.Context, alias string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.aliases[alias]; !ok {
+		return custom_error.TagAliasNotFound
+	}
+	delete(t.aliases, alias)
+	return nil
+}
+
+func (t *TagRepoFake) SelectNames(context context.Context) ([]string, error) {
+	return []string{}, nil
+}
+
+func (t *TagRepoFake) Merge(context context.Context, from, to string) error {
+	return nil
+}
+
+func (t *TagRepoFake) SelectPostTags(context context.Context) ([]model.Post, error) {
+	return []model.Post{}, nil
+}
+
+func (t *TagRepoFake) RenamePostTag(context context.Context, source, from, to string) (int64, error) {
+	return 0, nil
+}
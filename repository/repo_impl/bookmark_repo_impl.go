@@ -26,11 +26,11 @@ func NewBookmarkRepo(sql *db.Sql) repository.BookmarkRepo {
 func (b BookmarkRepoImpl) SelectAll(context context.Context, userId string) ([]model.Post, error) {
 	posts := []model.Post{}
 	err := b.sql.Db.SelectContext(context, &posts,
-		`SELECT 
-					posts.name, posts.link, posts.tag
-				FROM bookmarks 
+		`SELECT `+postColumns+`, true AS bookmarked
+				FROM bookmarks
 				INNER JOIN posts
-				ON bookmarks.user_id=$1 AND posts.name = bookmarks.post_name`, userId)
+				ON bookmarks.user_id=$1 AND posts.post_id = bookmarks.post_id
+				ORDER BY bookmarks.created_at DESC`, userId)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return posts, custom_error.BookmarkNotFound
@@ -40,30 +40,33 @@ func (b BookmarkRepoImpl) SelectAll(context context.Context, userId string) ([]m
 	return posts, nil
 }
 
-func (b BookmarkRepoImpl) Bookmark(context context.Context, bookmarkId, namePost, userId string) error {
+func (b BookmarkRepoImpl) Bookmark(context context.Context, bookmarkId string, postID int64, userId string) error {
 	statement := `INSERT INTO bookmarks(
-					bookmark_id, user_id, post_name, created_at, updated_at) 
+					bookmark_id, user_id, post_id, created_at, updated_at)
           		  VALUES($1, $2, $3, $4, $5)`
 	now := time.Now()
 	_, err := b.sql.Db.ExecContext(
 		context, statement, bookmarkId, userId,
-		namePost, now, now)
+		postID, now, now)
 	if err != nil {
 		if err, ok := err.(*pq.Error); ok {
 			if err.Code.Name() == "unique_violation" {
 				return custom_error.BookmarkConflic
 			}
+			if err.Code.Name() == "foreign_key_violation" {
+				return custom_error.PostNotFound
+			}
 		}
 		return custom_error.BookmarkFail
 	}
 	return nil
 }
 
-func (b BookmarkRepoImpl) Delete(context context.Context, namePost, userId string) error {
+func (b BookmarkRepoImpl) Delete(context context.Context, postID int64, userId string) error {
 	result := b.sql.Db.MustExecContext(
 		context,
-		"DELETE FROM bookmarks WHERE post_name = $1 AND user_id = $2",
-		namePost, userId)
+		"DELETE FROM bookmarks WHERE post_id = $1 AND user_id = $2",
+		postID, userId)
 
 	index, err := result.RowsAffected()
 	if index == 0 {
@@ -0,0 +1,131 @@
+package repo_impl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"devread/db"
+	"devread/repository"
+
+	"github.com/go-redis/redis"
+	"github.com/lib/pq"
+)
+
+const (
+	// pendingClicks - redis hash post id -> clicks not yet written to postgres
+	pendingClicks = "clicks:pending"
+	// clickSession - a visitor opening the same post again within this time is not counted
+	clickSession = 30 * time.Minute
+)
+
+var (
+	// count the click only if the visitor did not open the post during the session
+	recordScript = redis.NewScript(`
+		if redis.call("SET", KEYS[1], 1, "NX", "EX", ARGV[2]) then
+			redis.call("HINCRBY", KEYS[2], ARGV[1], 1)
+			return 1
+		end
+		return 0`)
+
+	// read and clear the pending clicks at once so no click is counted twice
+	takeScript = redis.NewScript(`
+		local clicks = redis.call("HGETALL", KEYS[1])
+		redis.call("DEL", KEYS[1])
+		return clicks`)
+)
+
+type ClickRepoImpl struct {
+	client *db.RedisDB
+	sql    *db.Sql
+}
+
+func NewClickRepo(client *db.RedisDB, sql *db.Sql) repository.ClickRepo {
+	return &ClickRepoImpl{
+		client: client,
+		sql:    sql,
+	}
+}
+
+// Record - counts a click in redis, returns false if the visitor already opened the post
+func (cl *ClickRepoImpl) Record(postID int64, visitor string) (bool, error) {
+	id := strconv.FormatInt(postID, 10)
+	seen := fmt.Sprintf("click:seen:%s:%s", id, visitor)
+	result, err := recordScript.Run(cl.client.Client, []string{seen, pendingClicks},
+		id, int(clickSession.Seconds())).Int()
+	if err != nil {
+		return false, err
+	}
+	return result == 1, nil
+}
+
+// Flush - writes the pending clicks to postgres in one statement per table,
+// returns how many posts got clicks
+func (cl *ClickRepoImpl) Flush(context context.Context) (int, error) {
+	values, err := takeScript.Run(cl.client.Client, []string{pendingClicks}).Result()
+	if err != nil {
+		return 0, err
+	}
+	pairs, _ := values.([]interface{})
+	if len(pairs) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, 0, len(pairs)/2)
+	counts := make([]int64, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		id, err := strconv.ParseInt(fmt.Sprint(pairs[i]), 10, 64)
+		if err != nil {
+			continue
+		}
+		count, err := strconv.ParseInt(fmt.Sprint(pairs[i+1]), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+		counts = append(counts, count)
+	}
+
+	if err := cl.save(context, ids, counts); err != nil {
+		// put the clicks back for the next flush
+		pipe := cl.client.Client.Pipeline()
+		for i, id := range ids {
+			pipe.HIncrBy(pendingClicks, strconv.FormatInt(id, 10), counts[i])
+		}
+		if _, restoreErr := pipe.Exec(); restoreErr != nil {
+			return 0, fmt.Errorf("%v, khôi phục lượt click thất bại: %v", err, restoreErr)
+		}
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+func (cl *ClickRepoImpl) save(context context.Context, ids, counts []int64) error {
+	tx, err := cl.sql.Db.BeginTxx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(context,
+		`INSERT INTO post_click_days(post_id, day, clicks)
+		SELECT c.post_id, current_date, c.clicks
+		FROM unnest($1::bigint[], $2::bigint[]) AS c(post_id, clicks)
+		WHERE EXISTS (SELECT 1 FROM posts WHERE posts.post_id = c.post_id)
+		ON CONFLICT (post_id, day) DO UPDATE SET clicks = post_click_days.clicks + EXCLUDED.clicks`,
+		pq.Array(ids), pq.Array(counts))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(context,
+		`UPDATE posts SET clicks = posts.clicks + c.clicks
+		FROM unnest($1::bigint[], $2::bigint[]) AS c(post_id, clicks)
+		WHERE posts.post_id = c.post_id`,
+		pq.Array(ids), pq.Array(counts))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,85 @@
+package repo_impl
+
+import (
+	"context"
+
+	"devread/custom_error"
+	"devread/db"
+	"devread/model"
+	"devread/repository"
+
+	"github.com/lib/pq"
+)
+
+type CrawlRunRepoImpl struct {
+	sql *db.Sql
+}
+
+func NewCrawlRunRepo(sql *db.Sql) repository.CrawlRunRepo {
+	return &CrawlRunRepoImpl{
+		sql: sql,
+	}
+}
+
+func (cr CrawlRunRepoImpl) Save(context context.Context, run model.CrawlRun) (model.CrawlRun, error) {
+	if run.Anomalies == nil {
+		run.Anomalies = pq.StringArray{}
+	}
+	statement := `
+		INSERT INTO crawl_runs(
+			run_id, source, mode, started_at, finished_at, pages_visited,
+			posts_found, posts_inserted, posts_updated, error_count, last_error,
+			empty_names, empty_links, empty_tags, anomalies)
+		VALUES(
+			:run_id, :source, :mode, :started_at, :finished_at, :pages_visited,
+			:posts_found, :posts_inserted, :posts_updated, :error_count, :last_error,
+			:empty_names, :empty_links, :empty_tags, :anomalies)
+	`
+	_, err := cr.sql.Db.NamedExecContext(context, statement, run)
+	if err != nil {
+		return run, custom_error.CrawlRunInsertFail
+	}
+	return run, nil
+}
+
+func (cr CrawlRunRepoImpl) SelectAll(context context.Context, source string, limit int) ([]model.CrawlRun, error) {
+	runs := []model.CrawlRun{}
+	err := cr.sql.Db.SelectContext(context, &runs,
+		`SELECT * FROM crawl_runs
+		WHERE LENGTH($1) = 0 OR source = $1
+		ORDER BY started_at DESC
+		LIMIT $2`, source, limit)
+	if err != nil {
+		return runs, err
+	}
+	return runs, nil
+}
+
+func (cr CrawlRunRepoImpl) SelectHealth(context context.Context) ([]model.SourceHealth, error) {
+	health := []model.SourceHealth{}
+	err := cr.sql.Db.SelectContext(context, &health,
+		`SELECT DISTINCT ON (last.source)
+			last.source,
+			last.mode AS last_mode,
+			last.started_at AS last_run_at,
+			last.posts_found AS last_posts_found,
+			last.error_count AS last_error_count,
+			last.last_error,
+			last.anomalies AS last_anomalies,
+			recent.avg_posts_found
+		FROM crawl_runs AS last
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(AVG(posts_found), 0) AS avg_posts_found
+			FROM (
+				SELECT posts_found FROM crawl_runs
+				WHERE source = last.source AND mode = last.mode
+				ORDER BY started_at DESC
+				LIMIT 10
+			) AS r
+		) AS recent
+		ORDER BY last.source, last.started_at DESC`)
+	if err != nil {
+		return health, err
+	}
+	return health, nil
+}
@@ -0,0 +1,51 @@
+package repo_impl
+
+import (
+	"time"
+
+	"devread/db"
+	"devread/repository"
+
+	"github.com/go-redis/redis"
+)
+
+// only the owner of a lock may extend or delete it
+var (
+	renewScript = redis.NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+		end
+		return 0`)
+
+	releaseScript = redis.NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("DEL", KEYS[1])
+		end
+		return 0`)
+)
+
+type LockRepoImpl struct {
+	client *db.RedisDB
+}
+
+func NewLockRepo(client *db.RedisDB) repository.LockRepo {
+	return &LockRepoImpl{
+		client: client,
+	}
+}
+
+func (l *LockRepoImpl) Acquire(key, owner string, ttl time.Duration) (bool, error) {
+	return l.client.Client.SetNX(key, owner, ttl).Result()
+}
+
+func (l *LockRepoImpl) Renew(key, owner string, ttl time.Duration) (bool, error) {
+	result, err := renewScript.Run(l.client.Client, []string{key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return result == 1, nil
+}
+
+func (l *LockRepoImpl) Release(key, owner string) error {
+	return releaseScript.Run(l.client.Client, []string{key}, owner).Err()
+}
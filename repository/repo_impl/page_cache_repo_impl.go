@@ -0,0 +1,73 @@
+package repo_impl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"devread/db"
+	"devread/model"
+	"devread/repository"
+
+	"github.com/go-redis/redis"
+)
+
+// pageCacheTTL - a page not crawled again within this time is dropped from the cache
+const pageCacheTTL = 7 * 24 * time.Hour
+
+type PageCacheRepoImpl struct {
+	client *db.RedisDB
+}
+
+func NewPageCacheRepo(client *db.RedisDB) repository.PageCacheRepo {
+	return &PageCacheRepoImpl{
+		client: client,
+	}
+}
+
+// pageKey - urls can be long, the key holds their hash
+func pageKey(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return "page:" + hex.EncodeToString(sum[:])
+}
+
+func (p *PageCacheRepoImpl) Get(url string) (model.CachedPage, bool, error) {
+	var page model.CachedPage
+	data, err := p.client.Client.Get(pageKey(url)).Bytes()
+	if err == redis.Nil {
+		return page, false, nil
+	}
+	if err != nil {
+		return page, false, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return page, false, err
+	}
+	defer reader.Close()
+	if err := json.NewDecoder(reader).Decode(&page); err != nil {
+		return page, false, err
+	}
+	// a hash collision would serve another page
+	if page.URL != url {
+		return model.CachedPage{}, false, nil
+	}
+	return page, true, nil
+}
+
+// Save - pages are stored gzipped, list pages shrink about ten times
+func (p *PageCacheRepoImpl) Save(page model.CachedPage) error {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(writer).Encode(page); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return p.client.Client.Set(pageKey(page.URL), buf.Bytes(), pageCacheTTL).Err()
+}
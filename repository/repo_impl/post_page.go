@@ -0,0 +1,111 @@
+package repo_impl
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"devread/custom_error"
+	"devread/model"
+)
+
+// postDate - sort key of "newest", posts without a published date use the crawl date
+const postDate = `COALESCE(posts.published_at, posts.crawled_at)`
+
+// cursor - sort key values of the last post of a page
+type cursor struct {
+	Sort   string    `json:"s"`
+	Score  float64   `json:"c,omitempty"`
+	Date   time.Time `json:"d"`
+	Name   string    `json:"n,omitempty"`
+	Source string    `json:"o,omitempty"`
+	Link   string    `json:"l"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value, sort string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return c, custom_error.InvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != sort {
+		return c, custom_error.InvalidCursor
+	}
+	return c, nil
+}
+
+// pageClauses - WHERE condition and ORDER BY of a keyset page, placeholders start at $n.
+// SortTrending needs post_trending joined to posts
+func pageClauses(page model.PageQuery, n int) (string, string, []interface{}, error) {
+	switch page.Sort {
+	case model.SortTrending, model.SortNewest, model.SortTitle, model.SortSource:
+	default:
+		return "", "", nil, custom_error.InvalidSort
+	}
+
+	var order string
+	switch page.Sort {
+	case model.SortTrending:
+		order = `post_trending.score DESC, posts.link DESC`
+	case model.SortNewest:
+		order = postDate + ` DESC, posts.link DESC`
+	case model.SortTitle:
+		order = `COALESCE(posts.name, '') ASC, posts.link ASC`
+	case model.SortSource:
+		order = `posts.source ASC, ` + postDate + ` DESC, posts.link DESC`
+	}
+
+	if page.Cursor == "" {
+		return "TRUE", order, nil, nil
+	}
+	c, err := decodeCursor(page.Cursor, page.Sort)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	switch page.Sort {
+	case model.SortTrending:
+		return fmt.Sprintf(`(post_trending.score, posts.link) < ($%d, $%d)`, n, n+1),
+			order, []interface{}{c.Score, c.Link}, nil
+	case model.SortNewest:
+		return fmt.Sprintf(`(%s, posts.link) < ($%d, $%d)`, postDate, n, n+1),
+			order, []interface{}{c.Date, c.Link}, nil
+	case model.SortTitle:
+		return fmt.Sprintf(`(COALESCE(posts.name, ''), posts.link) > ($%d, $%d)`, n, n+1),
+			order, []interface{}{c.Name, c.Link}, nil
+	default:
+		return fmt.Sprintf(`(posts.source > $%d OR (posts.source = $%d AND (%s, posts.link) < ($%d, $%d)))`, n, n, postDate, n+1, n+2),
+			order, []interface{}{c.Source, c.Date, c.Link}, nil
+	}
+}
+
+// toPage - cuts the extra post fetched to know whether there is a next page
+func toPage(posts []model.Post, page model.PageQuery) model.Page {
+	if len(posts) <= page.Limit {
+		return model.Page{Posts: posts}
+	}
+
+	posts = posts[:page.Limit]
+	last := posts[len(posts)-1]
+	date := last.CrawledAt
+	if last.PublishedAt != nil {
+		date = *last.PublishedAt
+	}
+	return model.Page{
+		Posts: posts,
+		NextCursor: encodeCursor(cursor{
+			Sort:   page.Sort,
+			Score:  last.TrendingScore,
+			Date:   date,
+			Name:   last.Name,
+			Source: last.Source,
+			Link:   last.Link,
+		}),
+	}
+}
@@ -3,15 +3,44 @@ package repo_impl
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
 
 	"devread/custom_error"
 	"devread/db"
+	"devread/helper"
 	"devread/model"
 	"devread/repository"
 
 	"github.com/lib/pq"
 )
 
+// postColumns - every column of posts returned to clients plus the names of its tags
+const postColumns = `posts.post_id, posts.name, posts.link, posts.tag, posts.source, posts.author,
+		posts.excerpt, posts.cover_image, posts.published_at, posts.crawled_at, posts.updated_at, posts.clicks,
+		posts.duplicate_of, COALESCE(posts.simhash, 0) AS simhash,
+		ARRAY(
+			SELECT tags.name FROM post_tags
+			INNER JOIN tags ON tags.tag_id = post_tags.tag_id
+			WHERE post_tags.post_link = posts.link
+			ORDER BY tags.name) AS tags`
+
+// refreshSearch - recomputes the full-text vector of the posts of $1 (array of links)
+// from their name, tags and excerpt
+const refreshSearch = `
+	UPDATE posts SET search_vector =
+		setweight(to_tsvector('simple', name_unaccent), 'A') ||
+		setweight(to_tsvector('simple', COALESCE((
+			SELECT string_agg(tags.name, ' ')
+			FROM post_tags
+			INNER JOIN tags ON tags.tag_id = post_tags.tag_id
+			WHERE post_tags.post_link = posts.link), '')), 'B') ||
+		setweight(to_tsvector('simple', excerpt_unaccent), 'C')
+	WHERE link = ANY($1)
+`
+
 type PostRepoImpl struct {
 	sql *db.Sql
 }
@@ -22,25 +51,38 @@ func NewPostRepo(sql *db.Sql) repository.PostRepo {
 	}
 }
 
-func (p PostRepoImpl) Save(context context.Context, post model.Post) (model.Post, error) {
-	statement := `INSERT INTO posts(name, link, tag) 
-          		  VALUES(:name, :link, :tag)`
-	_, err := p.sql.Db.NamedExecContext(context, statement, post)
+func (p PostRepoImpl) SelectByLink(context context.Context, link string) (model.Post, error) {
+	var post = model.Post{}
+	err := p.sql.Db.GetContext(context, &post,
+		`SELECT `+postColumns+` FROM posts WHERE link=$1`, link)
 	if err != nil {
-		if err, ok := err.(*pq.Error); ok {
-			if err.Code.Name() == "unique_violation" {
-				return post, custom_error.PostConflict
-			}
+		if err == sql.ErrNoRows {
+			return post, custom_error.PostNotFound
 		}
-		return post, custom_error.PostInsertFail
+		return post, err
 	}
 	return post, nil
 }
 
-func (p PostRepoImpl) SelectByLink(context context.Context, link string) (model.Post, error) {
+func (p PostRepoImpl) SelectKnownLinks(context context.Context, links []string) (map[string]bool, error) {
+	stored := []string{}
+	err := p.sql.Db.SelectContext(context, &stored,
+		`SELECT link FROM posts WHERE link = ANY($1)`, pq.Array(links))
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(stored))
+	for _, link := range stored {
+		known[link] = true
+	}
+	return known, nil
+}
+
+func (p PostRepoImpl) SelectByID(context context.Context, id int64) (model.Post, error) {
 	var post = model.Post{}
 	err := p.sql.Db.GetContext(context, &post,
-		`SELECT * FROM posts WHERE link=$1`, link)
+		`SELECT `+postColumns+` FROM posts WHERE post_id=$1`, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return post, custom_error.PostNotFound
@@ -50,50 +92,351 @@ func (p PostRepoImpl) SelectByLink(context context.Context, link string) (model.
 	return post, nil
 }
 
-func (p PostRepoImpl) SelectByTag(context context.Context, tag string) ([]model.Post, error) {
-	var posts = []model.Post{}
-	err := p.sql.Db.SelectContext(context, &posts,
-		`SELECT * FROM posts WHERE tag=$1`, tag)
+func (p PostRepoImpl) SelectByTag(context context.Context, tag string, page model.PageQuery) (model.Page, error) {
+	if page.Sort == model.SortTrending {
+		return model.Page{}, custom_error.InvalidSort
+	}
+	where, order, args, err := pageClauses(page, 3)
+	if err != nil {
+		return model.Page{}, err
+	}
 
+	posts := []model.Post{}
+	err = p.sql.Db.SelectContext(context, &posts,
+		`SELECT `+postColumns+` FROM posts
+		WHERE EXISTS (
+			SELECT 1 FROM post_tags
+			INNER JOIN tags ON tags.tag_id = post_tags.tag_id
+			WHERE post_tags.post_link = posts.link AND tags.name = $1)
+			AND posts.duplicate_of IS NULL
+			AND `+where+`
+		ORDER BY `+order+`
+		LIMIT $2`, append([]interface{}{tag, page.Limit + 1}, args...)...)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return posts, custom_error.PostNotFound
-		}
-		return posts, err
+		return model.Page{}, err
 	}
-	return posts, nil
+	return toPage(posts, page), nil
 }
 
-func (p PostRepoImpl) Update(context context.Context, post model.Post) (model.Post, error) {
-	sqlStatement := `
-		UPDATE posts
-		SET
-		    name = :name
-		WHERE link = :link
-	`
-	result, err := p.sql.Db.NamedExecContext(context, sqlStatement, post)
+func (p PostRepoImpl) SelectAll(context context.Context, page model.PageQuery) (model.Page, error) {
+	if page.Sort == model.SortTrending {
+		return model.Page{}, custom_error.InvalidSort
+	}
+	where, order, args, err := pageClauses(page, 2)
 	if err != nil {
-		return post, err
+		return model.Page{}, err
 	}
-	count, err := result.RowsAffected()
+
+	posts := []model.Post{}
+	err = p.sql.Db.SelectContext(context, &posts,
+		`SELECT `+postColumns+` FROM posts
+		WHERE posts.duplicate_of IS NULL
+			AND `+where+`
+		ORDER BY `+order+`
+		LIMIT $1`, append([]interface{}{page.Limit + 1}, args...)...)
 	if err != nil {
-		return post, custom_error.PostNotUpdated
+		return model.Page{}, err
 	}
-	if count == 0 {
-		return post, custom_error.PostNotUpdated
+	return toPage(posts, page), nil
+}
+
+// SelectSimilar - primary posts other than link whose name fingerprint shares a band with simhash
+func (p PostRepoImpl) SelectSimilar(context context.Context, link string, simhash int64) ([]model.Post, error) {
+	bands := helper.SimhashBands(uint64(simhash))
+	posts := []model.Post{}
+	err := p.sql.Db.SelectContext(context, &posts,
+		`SELECT `+postColumns+` FROM posts
+		WHERE posts.link <> $1
+			AND posts.duplicate_of IS NULL
+			AND ((posts.simhash >> 48) & 65535 = $2
+				OR (posts.simhash >> 32) & 65535 = $3
+				OR (posts.simhash >> 16) & 65535 = $4
+				OR posts.simhash & 65535 = $5)
+		ORDER BY posts.post_id`,
+		link, bands[0], bands[1], bands[2], bands[3])
+	if err != nil {
+		return posts, err
 	}
-	return post, nil
+	return posts, nil
 }
 
-func (p PostRepoImpl) SelectAll(context context.Context) ([]model.Post, error) {
+// SelectDedupe - every post with the fields the dedupe backfill needs, oldest first
+func (p PostRepoImpl) SelectDedupe(context context.Context) ([]model.Post, error) {
 	posts := []model.Post{}
 	err := p.sql.Db.SelectContext(context, &posts,
-		`SELECT * FROM posts`)
+		`SELECT post_id, COALESCE(name, '') AS name, link, source, duplicate_of
+		FROM posts ORDER BY post_id`)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return posts, custom_error.PostNotFound
-		}
 		return posts, err
 	}
 	return posts, nil
 }
+
+// MarkDuplicate - groups the post under primaryID, posts grouped under it follow
+func (p PostRepoImpl) MarkDuplicate(context context.Context, link string, primaryID int64) error {
+	_, err := p.sql.Db.ExecContext(context,
+		`UPDATE posts SET duplicate_of = $2
+		WHERE (link = $1 OR duplicate_of = (SELECT post_id FROM posts WHERE link = $1))
+			AND post_id <> $2`, link, primaryID)
+	return err
+}
+
+// UpdateLink - moves a post to its canonical link, tags and trending follow by cascade
+func (p PostRepoImpl) UpdateLink(context context.Context, from, to string) error {
+	_, err := p.sql.Db.ExecContext(context,
+		`UPDATE posts SET link = $2 WHERE link = $1`, from, to)
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok && err.Code.Name() == "unique_violation" {
+			return custom_error.PostConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// SaveSimhash - stores the fingerprint of the name of a post
+func (p PostRepoImpl) SaveSimhash(context context.Context, link string, simhash int64) error {
+	_, err := p.sql.Db.ExecContext(context,
+		`UPDATE posts SET simhash = $2 WHERE link = $1`, link, simhash)
+	return err
+}
+
+// changedTagLinks - links of $1 whose crawled tags ($1, $2 pairs) differ from the stored ones
+const changedTagLinks = `
+	WITH crawled AS (
+		SELECT DISTINCT t.link, tags.tag_id
+		FROM unnest($1::text[], $2::text[]) AS t(link, name)
+		INNER JOIN tags ON tags.name = t.name),
+	stored AS (
+		SELECT post_link AS link, tag_id FROM post_tags WHERE post_link = ANY($1))
+	SELECT DISTINCT link FROM (
+		(SELECT link, tag_id FROM crawled EXCEPT SELECT link, tag_id FROM stored)
+		UNION
+		(SELECT link, tag_id FROM stored EXCEPT SELECT link, tag_id FROM crawled)) AS diff
+`
+
+// upsertRow - a post in the JSON array read by jsonb_to_recordset
+type upsertRow struct {
+	Name            string     `json:"name"`
+	Link            string     `json:"link"`
+	Tag             string     `json:"tag"`
+	Source          string     `json:"source"`
+	Author          string     `json:"author"`
+	Excerpt         string     `json:"excerpt"`
+	CoverImage      string     `json:"cover_image"`
+	PublishedAt     *time.Time `json:"published_at"`
+	NameUnaccent    string     `json:"name_unaccent"`
+	ExcerptUnaccent string     `json:"excerpt_unaccent"`
+	Simhash         int64      `json:"simhash"`
+}
+
+// upsertPosts - inserts the posts or updates those whose name or metadata changed,
+// only the rows written are returned. Empty metadata never overwrites stored metadata
+const upsertPosts = `
+	INSERT INTO posts(
+		name, link, tag, source, author, excerpt, cover_image, published_at,
+		crawled_at, updated_at, name_unaccent, excerpt_unaccent, simhash)
+	SELECT
+		p.name, p.link, p.tag, p.source, p.author, p.excerpt, p.cover_image, p.published_at,
+		now(), now(), p.name_unaccent, p.excerpt_unaccent, p.simhash
+	FROM jsonb_to_recordset($1::jsonb) AS p(
+		name text, link text, tag text, source text, author text, excerpt text, cover_image text,
+		published_at timestamptz, name_unaccent text, excerpt_unaccent text, simhash bigint)
+	ON CONFLICT (link) DO UPDATE
+	SET
+		name = EXCLUDED.name,
+		tag = (CASE WHEN LENGTH(EXCLUDED.tag) = 0 THEN posts.tag ELSE EXCLUDED.tag END),
+		author = (CASE WHEN LENGTH(EXCLUDED.author) = 0 THEN posts.author ELSE EXCLUDED.author END),
+		excerpt = (CASE WHEN LENGTH(EXCLUDED.excerpt) = 0 THEN posts.excerpt ELSE EXCLUDED.excerpt END),
+		cover_image = (CASE WHEN LENGTH(EXCLUDED.cover_image) = 0 THEN posts.cover_image ELSE EXCLUDED.cover_image END),
+		published_at = COALESCE(EXCLUDED.published_at, posts.published_at),
+		updated_at = now(),
+		name_unaccent = EXCLUDED.name_unaccent,
+		excerpt_unaccent = (CASE WHEN LENGTH(EXCLUDED.excerpt_unaccent) = 0 THEN posts.excerpt_unaccent ELSE EXCLUDED.excerpt_unaccent END),
+		simhash = EXCLUDED.simhash
+	WHERE posts.name IS DISTINCT FROM EXCLUDED.name
+		OR (LENGTH(EXCLUDED.tag) > 0 AND EXCLUDED.tag <> posts.tag)
+		OR (LENGTH(EXCLUDED.author) > 0 AND EXCLUDED.author <> posts.author)
+		OR (LENGTH(EXCLUDED.excerpt) > 0 AND EXCLUDED.excerpt <> posts.excerpt)
+		OR (LENGTH(EXCLUDED.cover_image) > 0 AND EXCLUDED.cover_image <> posts.cover_image)
+		OR (EXCLUDED.published_at IS NOT NULL AND EXCLUDED.published_at IS DISTINCT FROM posts.published_at)
+	RETURNING post_id, link, (xmax = 0) AS inserted
+`
+
+// Upsert - UpsertMany of a single post
+func (p PostRepoImpl) Upsert(context context.Context, post model.Post) (model.UpsertResult, error) {
+	return p.UpsertMany(context, []model.Post{post})
+}
+
+// UpsertMany - saves the posts, their tags and source trending mark in one transaction
+// of a few statements whatever the number of posts
+func (p PostRepoImpl) UpsertMany(context context.Context, posts []model.Post) (model.UpsertResult, error) {
+	result := model.UpsertResult{}
+
+	// a link may appear twice on a page, ON CONFLICT can't update a row twice
+	seen := map[string]bool{}
+	unique := []model.Post{}
+	for _, post := range posts {
+		if seen[post.Link] {
+			continue
+		}
+		seen[post.Link] = true
+		unique = append(unique, post)
+	}
+	if len(unique) == 0 {
+		return result, nil
+	}
+
+	// rows are locked in link order so concurrent batches sharing posts don't deadlock
+	sort.Slice(unique, func(i, j int) bool {
+		return unique[i].Link < unique[j].Link
+	})
+	byLink := make(map[string]int, len(unique))
+	for i, post := range unique {
+		byLink[post.Link] = i
+	}
+
+	rows := make([]upsertRow, len(unique))
+	trending := []string{}
+	tagLinks, tagNames := []string{}, []string{}
+	for i, post := range unique {
+		rows[i] = upsertRow{
+			Name:            post.Name,
+			Link:            post.Link,
+			Tag:             post.Tag,
+			Source:          post.Source,
+			Author:          post.Author,
+			Excerpt:         post.Excerpt,
+			CoverImage:      post.CoverImage,
+			PublishedAt:     post.PublishedAt,
+			NameUnaccent:    helper.Fold(post.Name),
+			ExcerptUnaccent: helper.Fold(post.Excerpt),
+			Simhash:         int64(helper.Simhash(post.Name)),
+		}
+		if post.SourceTrending {
+			trending = append(trending, post.Link)
+		}
+		for _, tag := range post.Tags {
+			tagLinks = append(tagLinks, post.Link)
+			tagNames = append(tagNames, tag)
+		}
+	}
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return result, err
+	}
+
+	tx, err := p.sql.Db.BeginTxx(context, nil)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	written := []struct {
+		ID       int64  `db:"post_id"`
+		Link     string `db:"link"`
+		Inserted bool   `db:"inserted"`
+	}{}
+	if err := tx.SelectContext(context, &written, upsertPosts, string(data)); err != nil {
+		return result, fmt.Errorf("%w: %v", custom_error.PostInsertFail, err)
+	}
+	// only the rows written or whose tags changed need a new search vector
+	refresh := make([]string, 0, len(written))
+	for _, row := range written {
+		refresh = append(refresh, row.Link)
+	}
+
+	if len(trending) > 0 {
+		_, err = tx.ExecContext(context,
+			`UPDATE posts SET source_trending_at = now() WHERE link = ANY($1)`, pq.Array(trending))
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// posts crawled without tags keep the ones stored
+	if len(tagNames) > 0 {
+		_, err = tx.ExecContext(context,
+			`INSERT INTO tags(name) SELECT DISTINCT unnest($1::text[])
+			ON CONFLICT (name) DO NOTHING`, pq.Array(tagNames))
+		if err != nil {
+			return result, fmt.Errorf("%w: %v", custom_error.TagInsertFail, err)
+		}
+
+		changed := []string{}
+		err = tx.SelectContext(context, &changed, changedTagLinks, pq.Array(tagLinks), pq.Array(tagNames))
+		if err != nil {
+			return result, fmt.Errorf("%w: %v", custom_error.TagInsertFail, err)
+		}
+
+		if len(changed) > 0 {
+			_, err = tx.ExecContext(context,
+				`DELETE FROM post_tags WHERE post_link = ANY($1)`, pq.Array(changed))
+			if err != nil {
+				return result, fmt.Errorf("%w: %v", custom_error.TagInsertFail, err)
+			}
+
+			_, err = tx.ExecContext(context,
+				`INSERT INTO post_tags(post_link, tag_id)
+				SELECT DISTINCT t.link, tags.tag_id
+				FROM unnest($1::text[], $2::text[]) AS t(link, name)
+				INNER JOIN tags ON tags.name = t.name
+				WHERE t.link = ANY($3)`, pq.Array(tagLinks), pq.Array(tagNames), pq.Array(changed))
+			if err != nil {
+				return result, fmt.Errorf("%w: %v", custom_error.TagInsertFail, err)
+			}
+			refresh = append(refresh, changed...)
+		}
+	}
+
+	if len(refresh) > 0 {
+		if _, err = tx.ExecContext(context, refreshSearch, pq.Array(refresh)); err != nil {
+			return result, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+
+	for _, row := range written {
+		if !row.Inserted {
+			result.Updated++
+			continue
+		}
+		post := unique[byLink[row.Link]]
+		post.ID = row.ID
+		result.Inserted = append(result.Inserted, post)
+	}
+	return result, nil
+}
+
+func (p PostRepoImpl) Search(context context.Context, query model.SearchQuery) ([]model.SearchResult, error) {
+	results := []model.SearchResult{}
+	err := p.sql.Db.SelectContext(context, &results,
+		`SELECT `+postColumns+`,
+			ts_rank(posts.search_vector, q) AS rank
+		FROM posts, websearch_to_tsquery('simple', $1) AS q
+		WHERE posts.search_vector @@ q
+			AND posts.duplicate_of IS NULL
+			AND (LENGTH($2) = 0 OR posts.source = $2)
+			AND (LENGTH($3) = 0 OR EXISTS (
+				SELECT 1 FROM post_tags
+				INNER JOIN tags ON tags.tag_id = post_tags.tag_id
+				WHERE post_tags.post_link = posts.link AND tags.name = $3))
+			AND ($4::timestamptz IS NULL OR posts.published_at >= $4)
+			AND ($5::timestamptz IS NULL OR posts.published_at < $5)
+		ORDER BY rank DESC, posts.published_at DESC NULLS LAST
+		LIMIT $6`,
+		helper.Fold(query.Query), query.Source, query.Tag, query.From, query.To, query.Limit)
+	if err != nil {
+		return results, err
+	}
+
+	// highlight in Go: the vector is built from folded text, the client wants the original
+	terms := helper.SearchTerms(query.Query)
+	for i := range results {
+		results[i].NameHighlight = helper.Highlight(results[i].Name, terms)
+		results[i].ExcerptHighlight = helper.Highlight(results[i].Excerpt, terms)
+	}
+	return results, nil
+}
@@ -0,0 +1,125 @@
+package repo_impl
+
+import (
+	"context"
+	"time"
+
+	"devread/custom_error"
+	"devread/db"
+	"devread/model"
+	"devread/repository"
+)
+
+type TagRepoImpl struct {
+	sql *db.Sql
+}
+
+func NewTagRepo(sql *db.Sql) repository.TagRepo {
+	return &TagRepoImpl{
+		sql: sql,
+	}
+}
+
+func (t TagRepoImpl) SelectAliases(context context.Context) ([]model.TagAlias, error) {
+	aliases := []model.TagAlias{}
+	err := t.sql.Db.SelectContext(context, &aliases,
+		`SELECT * FROM tag_aliases ORDER BY tag, alias`)
+	if err != nil {
+		return aliases, err
+	}
+	return aliases, nil
+}
+
+func (t TagRepoImpl) SaveAlias(context context.Context, alias model.TagAlias) (model.TagAlias, error) {
+	statement := `
+		INSERT INTO tag_aliases(alias, tag, created_at)
+		VALUES(:alias, :tag, :created_at)
+		ON CONFLICT (alias) DO UPDATE SET tag = EXCLUDED.tag
+	`
+	alias.CreatedAt = time.Now()
+	_, err := t.sql.Db.NamedExecContext(context, statement, alias)
+	if err != nil {
+		return alias, custom_error.TagAliasInsertFail
+	}
+	return alias, nil
+}
+
+func (t TagRepoImpl) DeleteAlias(context context.Context, alias string) error {
+	result, err := t.sql.Db.ExecContext(context,
+		`DELETE FROM tag_aliases WHERE alias = $1`, alias)
+	if err != nil {
+		return err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return custom_error.TagAliasNotFound
+	}
+	return nil
+}
+
+func (t TagRepoImpl) SelectNames(context context.Context) ([]string, error) {
+	names := []string{}
+	err := t.sql.Db.SelectContext(context, &names,
+		`SELECT name FROM tags ORDER BY name`)
+	if err != nil {
+		return names, err
+	}
+	return names, nil
+}
+
+// Merge - moves every post of tag "from" to tag "to" then deletes "from"
+func (t TagRepoImpl) Merge(context context.Context, from, to string) error {
+	tx, err := t.sql.Db.BeginTxx(context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(context,
+		`INSERT INTO tags(name) VALUES($1) ON CONFLICT (name) DO NOTHING`, to)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(context,
+		`INSERT INTO post_tags(post_link, tag_id)
+		SELECT post_tags.post_link, target.tag_id
+		FROM post_tags
+		INNER JOIN tags AS source ON source.tag_id = post_tags.tag_id AND source.name = $1
+		CROSS JOIN tags AS target
+		WHERE target.name = $2
+		ON CONFLICT DO NOTHING`, from, to)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(context,
+		`DELETE FROM tags WHERE name = $1`, from)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (t TagRepoImpl) SelectPostTags(context context.Context) ([]model.Post, error) {
+	posts := []model.Post{}
+	err := t.sql.Db.SelectContext(context, &posts,
+		`SELECT DISTINCT source, COALESCE(tag, '') AS tag FROM posts`)
+	if err != nil {
+		return posts, err
+	}
+	return posts, nil
+}
+
+func (t TagRepoImpl) RenamePostTag(context context.Context, source, from, to string) (int64, error) {
+	result, err := t.sql.Db.ExecContext(context,
+		`UPDATE posts SET tag = $3 WHERE source = $1 AND COALESCE(tag, '') = $2`,
+		source, from, to)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,104 @@
+package repo_impl
+
+import (
+	"context"
+	"time"
+
+	"devread/db"
+	"devread/model"
+	"devread/repository"
+)
+
+// weights of the trending score:
+// (1 + clicks*clickWeight + bookmarks*bookmarkWeight + sourceTrendingWeight) / (age in hours + 2)^gravity
+const (
+	clickWeight          = 0.5
+	bookmarkWeight       = 3.0
+	sourceTrendingWeight = 5.0
+	gravity              = 1.5
+)
+
+type TrendRepoImpl struct {
+	sql *db.Sql
+}
+
+func NewTrendRepo(sql *db.Sql) repository.TrendRepo {
+	return &TrendRepoImpl{
+		sql: sql,
+	}
+}
+
+// Refresh - recomputes the score of every post published, clicked, bookmarked or trending
+// on its source during the window, returns how many posts were scored
+func (t TrendRepoImpl) Refresh(context context.Context, window model.TrendWindow) (int64, error) {
+	tx, err := t.sql.Db.BeginTxx(context, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(context,
+		`DELETE FROM post_trending WHERE period = $1`, window.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	since := time.Now().Add(-window.Duration)
+	result, err := tx.ExecContext(context,
+		`INSERT INTO post_trending(period, post_link, score, clicks, bookmarks, source_trending, computed_at)
+		SELECT $1, posts.link,
+			(1 + COALESCE(clicked.clicks, 0) * $6::float8
+				+ COALESCE(recent.bookmarks, 0) * $3::float8
+				+ (CASE WHEN posts.source_trending_at >= $2 THEN $4::float8 ELSE 0 END))
+			/ power(GREATEST(EXTRACT(EPOCH FROM now() - COALESCE(posts.published_at, posts.crawled_at)), 0) / 3600 + 2, $5::float8),
+			COALESCE(clicked.clicks, 0),
+			COALESCE(recent.bookmarks, 0),
+			COALESCE(posts.source_trending_at >= $2, false),
+			now()
+		FROM posts
+		LEFT JOIN (
+			SELECT post_id, COUNT(*) AS bookmarks FROM bookmarks
+			WHERE created_at >= $2
+			GROUP BY post_id
+		) AS recent ON recent.post_id = posts.post_id
+		LEFT JOIN (
+			SELECT post_id, SUM(clicks) AS clicks FROM post_click_days
+			WHERE day >= $2::date
+			GROUP BY post_id
+		) AS clicked ON clicked.post_id = posts.post_id
+		WHERE posts.duplicate_of IS NULL
+			AND (COALESCE(posts.published_at, posts.crawled_at) >= $2
+				OR recent.bookmarks > 0
+				OR clicked.clicks > 0
+				OR posts.source_trending_at >= $2)`,
+		window.Name, since, bookmarkWeight, sourceTrendingWeight, gravity, clickWeight)
+	if err != nil {
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, tx.Commit()
+}
+
+func (t TrendRepoImpl) SelectPosts(context context.Context, window string, page model.PageQuery) (model.Page, error) {
+	where, order, args, err := pageClauses(page, 3)
+	if err != nil {
+		return model.Page{}, err
+	}
+
+	posts := []model.Post{}
+	err = t.sql.Db.SelectContext(context, &posts,
+		`SELECT `+postColumns+`, post_trending.score AS trending_score
+		FROM post_trending
+		INNER JOIN posts ON posts.link = post_trending.post_link
+		WHERE post_trending.period = $1
+			AND `+where+`
+		ORDER BY `+order+`
+		LIMIT $2`, append([]interface{}{window, page.Limit + 1}, args...)...)
+	if err != nil {
+		return model.Page{}, err
+	}
+	return toPage(posts, page), nil
+}
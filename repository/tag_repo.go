@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"devread/model"
+)
+
+type TagRepo interface {
+	SelectAliases(context context.Context) ([]model.TagAlias, error)
+	SaveAlias(context context.Context, alias model.TagAlias) (model.TagAlias, error)
+	DeleteAlias(context context.Context, alias string) error
+	SelectNames(context context.Context) ([]string, error)
+	Merge(context context.Context, from, to string) error
+	SelectPostTags(context context.Context) ([]model.Post, error)
+	RenamePostTag(context context.Context, source, from, to string) (int64, error)
+}
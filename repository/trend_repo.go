@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"devread/model"
+)
+
+type TrendRepo interface {
+	Refresh(context context.Context, window model.TrendWindow) (int64, error)
+	SelectPosts(context context.Context, window string, page model.PageQuery) (model.Page, error)
+}
@@ -8,9 +8,11 @@ import (
 )
 
 type API struct {
-	Echo        *echo.Echo
-	UserHandler handler.UserHandler
-	PostHandler handler.PostHandler
+	Echo         *echo.Echo
+	UserHandler  handler.UserHandler
+	PostHandler  handler.PostHandler
+	CrawlHandler handler.CrawlHandler
+	TagHandler   handler.TagHandler
 }
 
 func (api *API) SetupRouter() {
@@ -60,4 +62,31 @@ func (api *API) SetupRouter() {
 	)
 	post.GET("trend", api.PostHandler.PostTrending)
 	post.GET("posts", api.PostHandler.SearchPost)
+	post.GET("search", api.PostHandler.Search)
+	post.GET("go/:id", api.PostHandler.Go, middleware.OptionalJWTMiddleware())
+
+	// crawl admin
+	crawl := api.Echo.Group("/admin/crawl",
+		middleware.CORSMiddleware(),
+		middleware.JWTMiddleware(),
+		middleware.AdminMiddleware(),
+		middleware.HeadersMiddleware(),
+		middleware.HeadersAccept(),
+		middleware.GzipMiddleware(),
+	)
+	crawl.GET("/runs", api.CrawlHandler.CrawlRuns)
+	crawl.GET("/health", api.CrawlHandler.CrawlHealth)
+
+	// tag admin
+	tag := api.Echo.Group("/admin/tags",
+		middleware.CORSMiddleware(),
+		middleware.JWTMiddleware(),
+		middleware.AdminMiddleware(),
+		middleware.HeadersMiddleware(),
+		middleware.HeadersAccept(),
+		middleware.GzipMiddleware(),
+	)
+	tag.GET("/aliases", api.TagHandler.TagAliases)
+	tag.POST("/aliases", api.TagHandler.SaveTagAlias)
+	tag.DELETE("/aliases/:alias", api.TagHandler.DelTagAlias)
 }
@@ -0,0 +1,259 @@
+package scheduler
+
+import (
+	"devread/custom_error"
+	"devread/repository"
+
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// Spec - when a task runs
+type Spec struct {
+	// Cron - standard cron expression ("0 */6 * * *") or descriptor ("@every 1h", "@daily")
+	Cron string
+	// Jitter - random delay added to every run so instances don't fire together
+	Jitter time.Duration
+	// RunOnStartup - run once as soon as the scheduler starts
+	RunOnStartup bool
+}
+
+// Every - cron descriptor for a fixed interval
+func Every(d time.Duration) string {
+	return "@every " + d.String()
+}
+
+// Task - a named job run on its Spec, ctx is cancelled when the process shuts down
+type Task struct {
+	Name string
+	Spec Spec
+	Run  func(ctx context.Context)
+	// Lock - tasks sharing a lock never run at once, even on different instances. Name by default
+	Lock string
+}
+
+type entry struct {
+	task     Task
+	schedule cron.Schedule
+}
+
+const (
+	// leaseTTL - how long a crawl lock lives without being renewed
+	leaseTTL = 2 * time.Minute
+	// lockedRetry - delay before a scheduled run that found its lock taken is tried again
+	lockedRetry = 5 * time.Minute
+)
+
+type Scheduler struct {
+	envPrefix string
+	entries   []entry
+	logger    *zap.Logger
+
+	lockRepo repository.LockRepo
+	owner    string
+
+	running sync.WaitGroup
+}
+
+// NewScheduler - creates a scheduler reading overrides from env vars starting with envPrefix
+func NewScheduler(envPrefix string, logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		envPrefix: envPrefix,
+		logger:    logger,
+	}
+}
+
+// UseLock - makes every run take a distributed lock so only one instance runs a task per tick
+func (s *Scheduler) UseLock(lockRepo repository.LockRepo, owner string) {
+	s.lockRepo = lockRepo
+	s.owner = owner
+}
+
+// Add - registers a task after applying overrides from the environment:
+//
+//	<PREFIX>_<NAME>_SCHEDULE    cron expression or descriptor
+//	<PREFIX>_<NAME>_JITTER      duration, e.g. 10m
+//	<PREFIX>_<NAME>_ON_STARTUP  true/false
+func (s *Scheduler) Add(task Task) error {
+	spec, err := s.override(task.Name, task.Spec)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := cron.ParseStandard(spec.Cron)
+	if err != nil {
+		return fmt.Errorf("lịch chạy %q của %s không hợp lệ: %w", spec.Cron, task.Name, err)
+	}
+
+	task.Spec = spec
+	if task.Lock == "" {
+		task.Lock = task.Name
+	}
+	s.entries = append(s.entries, entry{
+		task:     task,
+		schedule: schedule,
+	})
+	return nil
+}
+
+// Start - runs every task on its own goroutine until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, e := range s.entries {
+		s.running.Add(1)
+		go s.loop(ctx, e)
+	}
+}
+
+// Wait - blocks until every task started by Start has returned after ctx was cancelled
+func (s *Scheduler) Wait() {
+	s.running.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	defer s.running.Done()
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	var err error
+	if e.task.Spec.RunOnStartup {
+		err = s.run(ctx, e, false)
+	}
+
+	for ctx.Err() == nil {
+		next := e.schedule.Next(time.Now())
+		if e.task.Spec.Jitter > 0 {
+			next = next.Add(time.Duration(random.Int63n(int64(e.task.Spec.Jitter))))
+		}
+		// a task sharing the lock is running: try again soon instead of skipping the tick
+		if err == custom_error.TaskLocked {
+			next = time.Now().Add(lockedRetry)
+		}
+		s.logger.Sugar().Info("Lần chạy tiếp theo của ", e.task.Name, ": ", next.Format("2006-01-02 15:04:05"))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			err = s.run(ctx, e, false)
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+}
+
+// RunNow - runs a task immediately, even if it already ran in this tick,
+// returns custom_error.TaskNotFound or custom_error.TaskLocked when it did not run
+func (s *Scheduler) RunNow(ctx context.Context, name string) error {
+	for _, e := range s.entries {
+		if e.task.Name == name {
+			return s.run(ctx, e, true)
+		}
+	}
+	return custom_error.TaskNotFound
+}
+
+// run - the lock is held only while the task runs. A scheduled run also marks the tick
+// as done for half the schedule gap, so instances firing later in the same tick
+// (because of jitter) skip it, manual runs ignore the mark
+func (s *Scheduler) run(ctx context.Context, e entry, manual bool) error {
+	if s.lockRepo == nil {
+		e.task.Run(ctx)
+		return nil
+	}
+
+	mark := strings.ToLower("last:" + s.envPrefix + ":" + e.task.Name)
+	if !manual {
+		next := e.schedule.Next(time.Now())
+		cooldown := e.schedule.Next(next).Sub(next) / 2
+		marked, err := s.lockRepo.Acquire(mark, s.owner, cooldown)
+		if err == nil && !marked {
+			s.logger.Sugar().Info("Bỏ qua ", e.task.Name, ": đã chạy trong lượt này")
+			return custom_error.TaskAlreadyRan
+		}
+		if err != nil {
+			s.logger.Error("Đánh dấu lượt chạy thất bại ", zap.String("task", e.task.Name), zap.Error(err))
+		}
+	}
+
+	key := strings.ToLower("lock:" + s.envPrefix + ":" + e.task.Lock)
+	acquired, err := s.lockRepo.Acquire(key, s.owner, leaseTTL)
+	if err != nil {
+		// redis down: crawling twice is better than not crawling
+		s.logger.Error("Lấy khóa thất bại, vẫn chạy ", zap.String("task", e.task.Name), zap.Error(err))
+		e.task.Run(ctx)
+		return nil
+	}
+	if !acquired {
+		s.logger.Sugar().Info("Bỏ qua ", e.task.Name, ": đang được chạy ở lượt khác")
+		// the tick did not run here, leave it to the retry
+		if !manual {
+			s.lockRepo.Release(mark, s.owner)
+		}
+		return custom_error.TaskLocked
+	}
+
+	stop := make(chan struct{})
+	go s.renew(key, e.task.Name, stop)
+	e.task.Run(ctx)
+	close(stop)
+
+	if err := s.lockRepo.Release(key, s.owner); err != nil {
+		s.logger.Error("Trả khóa thất bại ", zap.String("task", e.task.Name), zap.Error(err))
+	}
+	// interrupted: let another instance run the task right away
+	if ctx.Err() != nil && !manual {
+		if err := s.lockRepo.Release(mark, s.owner); err != nil {
+			s.logger.Error("Xoá đánh dấu lượt chạy thất bại ", zap.String("task", e.task.Name), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) renew(key, name string, stop chan struct{}) {
+	ticker := time.NewTicker(leaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ok, err := s.lockRepo.Renew(key, s.owner, leaseTTL)
+			if err != nil || !ok {
+				s.logger.Error("Mất khóa khi đang chạy ", zap.String("task", name), zap.Error(err))
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (s *Scheduler) override(name string, spec Spec) (Spec, error) {
+	key := strings.ToUpper(s.envPrefix + "_" + name)
+
+	if value := os.Getenv(key + "_SCHEDULE"); value != "" {
+		spec.Cron = value
+	}
+
+	if value := os.Getenv(key + "_JITTER"); value != "" {
+		jitter, err := time.ParseDuration(value)
+		if err != nil {
+			return spec, fmt.Errorf("%s_JITTER không hợp lệ: %w", key, err)
+		}
+		spec.Jitter = jitter
+	}
+
+	if value := os.Getenv(key + "_ON_STARTUP"); value != "" {
+		onStartup, err := strconv.ParseBool(value)
+		if err != nil {
+			return spec, fmt.Errorf("%s_ON_STARTUP không hợp lệ: %w", key, err)
+		}
+		spec.RunOnStartup = onStartup
+	}
+	return spec, nil
+}
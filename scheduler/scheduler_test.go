@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"devread/custom_error"
+	"devread/repository/repo_fake"
+
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestRunCooldown(t *testing.T) {
+	locks := repo_fake.NewLockRepo()
+	runs := 0
+	newInstance := func(owner string) *Scheduler {
+		s := NewScheduler("TEST", zap.NewNop())
+		s.UseLock(locks, owner)
+		err := s.Add(Task{Name: "viblo", Spec: Spec{Cron: Every(96 * time.Hour)}, Run: func(ctx context.Context) {
+			runs++
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	first, second := newInstance("a"), newInstance("b")
+	ctx := context.Background()
+
+	if err := first.run(ctx, first.entries[0], false); err != nil {
+		t.Fatalf("first scheduled run: %v", err)
+	}
+	// same tick on another instance, fired later because of jitter
+	if err := second.run(ctx, second.entries[0], false); err != custom_error.TaskAlreadyRan {
+		t.Errorf("second scheduled run in the same tick: %v", err)
+	}
+	// the lock is released once the run is over, manual runs ignore the tick
+	if err := second.RunNow(ctx, "viblo"); err != nil {
+		t.Errorf("manual run after a scheduled run: %v", err)
+	}
+	if err := first.RunNow(ctx, "tags"); err != custom_error.TaskNotFound {
+		t.Errorf("RunNow of an unknown task: %v", err)
+	}
+	if runs != 2 {
+		t.Errorf("task ran %d times, want 2", runs)
+	}
+}
+
+func TestRunNowLocked(t *testing.T) {
+	locks := repo_fake.NewLockRepo()
+	s := NewScheduler("TEST", zap.NewNop())
+	s.UseLock(locks, "a")
+	if err := s.Add(Task{Name: "viblo", Spec: Spec{Cron: Every(time.Hour)}, Run: func(ctx context.Context) {}}); err != nil {
+		t.Fatal(err)
+	}
+
+	locks.Acquire("lock:test:viblo", "b", time.Minute)
+	if err := s.RunNow(context.Background(), "viblo"); err != custom_error.TaskLocked {
+		t.Errorf("RunNow while locked: %v", err)
+	}
+}
+
+func TestSharedLock(t *testing.T) {
+	locks := repo_fake.NewLockRepo()
+	s := NewScheduler("TEST", zap.NewNop())
+	s.UseLock(locks, "a")
+	var full error
+	tasks := []Task{
+		{Name: "viblo", Spec: Spec{Cron: Every(time.Hour)}, Lock: "viblo", Run: func(ctx context.Context) {
+			full = s.RunNow(ctx, "viblo_full")
+		}},
+		{Name: "viblo_full", Spec: Spec{Cron: Every(time.Hour)}, Lock: "viblo", Run: func(ctx context.Context) {}},
+	}
+	for _, task := range tasks {
+		if err := s.Add(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.RunNow(context.Background(), "viblo"); err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	if full != custom_error.TaskLocked {
+		t.Errorf("full run during the incremental one: %v, want TaskLocked", full)
+	}
+	if err := s.RunNow(context.Background(), "viblo_full"); err != nil {
+		t.Errorf("full run once the lock is free: %v", err)
+	}
+
+	// a scheduled run that found the lock taken does not keep the tick
+	locks.Acquire("lock:test:viblo", "b", time.Minute)
+	if err := s.run(context.Background(), s.entries[1], false); err != custom_error.TaskLocked {
+		t.Fatalf("scheduled run while locked: %v", err)
+	}
+	locks.Release("lock:test:viblo", "b")
+	if err := s.run(context.Background(), s.entries[1], false); err != nil {
+		t.Errorf("scheduled retry: %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"devread/crawler"
+	"devread/handler"
+	"devread/helper"
+	"devread/repository"
+	"devread/repository/repo_impl"
+	"devread/router"
+	"devread/scheduler"
+	"devread/tagnorm"
+
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	echoSwagger "github.com/swaggo/echo-swagger"
+	"go.uber.org/zap"
+)
+
+// shutdownTimeout - time given to in-flight requests when stopping, heroku kills the dyno 30s after SIGTERM
+const shutdownTimeout = 25 * time.Second
+
+// serve - runs the HTTP API, optionally with the crawler in the same process
+func serve(ctx context.Context, log *zap.Logger, args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	withCrawler := flags.Bool("with-crawler", false, "chạy crawler theo lịch trong cùng process")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	// also stops the schedulers when the server fails to start
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := connectRedis(log)
+	sql := connectPostgres(log)
+	defer sql.Close()
+
+	e := echo.New()
+	e.GET("/swagger/*", echoSwagger.WrapHandler)
+
+	customValidator := helper.NewCustomValidator()
+	customValidator.RegisterValidate()
+
+	e.Validator = customValidator
+
+	userHandler := handler.UserHandler{
+		UserRepo: repo_impl.NewUserRepo(sql),
+		AuthRepo: repo_impl.NewAuthenRepo(client),
+		Logger:   log,
+	}
+
+	tagRepo := repo_impl.NewTagRepo(sql)
+	tagNormalizer := tagnorm.NewNormalizer(tagRepo, log)
+	if err := tagNormalizer.Reload(ctx); err != nil {
+		log.Error("Tải alias tag thất bại ", zap.Error(err))
+	}
+
+	clickRepo := repo_impl.NewClickRepo(client, sql)
+	clickScheduler, err := newClickScheduler(log, clickRepo)
+	if err != nil {
+		log.Error("Lập lịch ghi lượt click thất bại ", zap.Error(err))
+		return 1
+	}
+	clickScheduler.Start(ctx)
+	schedulers := []*scheduler.Scheduler{clickScheduler}
+	// stops the schedulers and waits for their running tasks before closing the databases
+	defer func() {
+		cancel()
+		for _, s := range schedulers {
+			s.Wait()
+		}
+	}()
+
+	postHandler := handler.PostHandler{
+		PostRepo:      repo_impl.NewPostRepo(sql),
+		TrendRepo:     repo_impl.NewTrendRepo(sql),
+		ClickRepo:     clickRepo,
+		AuthRepo:      repo_impl.NewAuthenRepo(client),
+		BookmarkRepo:  repo_impl.NewBookmarkRepo(sql),
+		TagNormalizer: tagNormalizer,
+		Logger:        log,
+	}
+
+	crawlHandler := handler.CrawlHandler{
+		CrawlRunRepo: repo_impl.NewCrawlRunRepo(sql),
+		Logger:       log,
+	}
+
+	tagHandler := handler.TagHandler{
+		TagRepo:       tagRepo,
+		TagNormalizer: tagNormalizer,
+		Logger:        log,
+	}
+
+	api := router.API{
+		Echo:         e,
+		UserHandler:  userHandler,
+		PostHandler:  postHandler,
+		CrawlHandler: crawlHandler,
+		TagHandler:   tagHandler,
+	}
+	api.SetupRouter()
+
+	if *withCrawler {
+		if err := loadSources(); err != nil {
+			log.Error("Đọc danh sách nguồn thất bại ", zap.Error(err))
+			return 1
+		}
+		crawlScheduler, err := newCrawlScheduler(log, client, sql, crawler.Sources(), nil)
+		if err != nil {
+			log.Error("Lập lịch crawler thất bại ", zap.Error(err))
+			return 1
+		}
+		crawlScheduler.Start(ctx)
+		schedulers = append(schedulers, crawlScheduler)
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- e.Start(":" + os.Getenv("PORT"))
+	}()
+
+	select {
+	case err := <-serverErr:
+		log.Error("Server dừng ", zap.Error(err))
+		return 1
+	case <-ctx.Done():
+	}
+
+	log.Info("Đang dừng server ")
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		log.Error("Dừng server thất bại ", zap.Error(err))
+		return 1
+	}
+	if err := <-serverErr; err != nil && err != http.ErrServerClosed {
+		log.Error("Server dừng ", zap.Error(err))
+		return 1
+	}
+	return 0
+}
+
+// newClickScheduler - writes the clicks buffered in redis to postgres,
+// every API instance may flush since redis hands each click to one of them
+func newClickScheduler(log *zap.Logger, clickRepo repository.ClickRepo) (*scheduler.Scheduler, error) {
+	clickScheduler := scheduler.NewScheduler("CLICKS", log)
+	err := clickScheduler.Add(scheduler.Task{
+		Name: "flush",
+		Spec: scheduler.Spec{
+			Cron:   scheduler.Every(time.Minute),
+			Jitter: 10 * time.Second,
+		},
+		Run: func(ctx context.Context) {
+			count, err := clickRepo.Flush(ctx)
+			if err != nil {
+				log.Error("Ghi lượt click thất bại ", zap.Error(err))
+				return
+			}
+			if count > 0 {
+				log.Info("Ghi lượt click xong ", zap.Int("posts", count))
+			}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clickScheduler, nil
+}
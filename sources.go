@@ -0,0 +1,134 @@
+package main
+
+import (
+	"devread/crawler"
+
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// sources - "dry-run" prints the posts a source extracts from its first pages, nothing is stored
+func sources(ctx context.Context, log *zap.Logger, args []string) int {
+	if len(args) == 0 || args[0] != "dry-run" {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	flags := flag.NewFlagSet("sources dry-run", flag.ContinueOnError)
+	file := flags.String("file", "", "file khai báo nguồn, mặc định CRAWL_SOURCES_FILE hoặc sources.json")
+	pages := flags.Int("pages", 1, "số trang đọc trong mỗi danh sách")
+	asJSON := flags.Bool("json", false, "in bài viết dạng JSON")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 || *pages < 1 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	var err error
+	if *file != "" {
+		// LoadSources skips a missing file, only right for the default path
+		if _, err := os.Stat(*file); err != nil {
+			fmt.Fprintf(os.Stderr, "Không đọc được file khai báo nguồn %s: %v\n", *file, err)
+			return 2
+		}
+		err = crawler.LoadSources(*file)
+	} else {
+		err = loadSources()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	src, ok := crawler.Lookup(flags.Arg(0))
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Nguồn %q không tồn tại\n", flags.Arg(0))
+		return 2
+	}
+
+	policy, err := crawler.PolicyFromEnv()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	policy.Logger = log
+	crawler.UsePolicy(policy)
+
+	results := crawler.DryRun(ctx, src, *pages)
+	if *asJSON {
+		err = printPostsJSON(os.Stdout, results)
+	} else {
+		printDryRun(os.Stdout, results)
+	}
+	if err != nil {
+		log.Error("In kết quả thất bại ", zap.Error(err))
+		return 1
+	}
+
+	for _, result := range results {
+		if result.Err != nil || len(result.Posts) == 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+func printDryRun(w io.Writer, results []crawler.PageResult) {
+	for _, result := range results {
+		fmt.Fprintf(w, "%s: %d bài viết\n", result.URL, len(result.Posts))
+		if result.Err != nil {
+			fmt.Fprintf(w, "  lỗi: %v\n", result.Err)
+		}
+		if len(result.Posts) == 0 {
+			continue
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  NAME\tLINK\tTAGS\tAUTHOR\tPUBLISHED")
+		for _, post := range result.Posts {
+			published := "-"
+			if post.PublishedAt != nil {
+				published = post.PublishedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n",
+				orDash(post.Name), orDash(post.Link), orDash(strings.Join(post.Tags, ", ")), orDash(post.Author), published)
+		}
+		tw.Flush()
+	}
+}
+
+func printPostsJSON(w io.Writer, results []crawler.PageResult) error {
+	type page struct {
+		URL   string      `json:"url"`
+		Error string      `json:"error,omitempty"`
+		Posts interface{} `json:"posts"`
+	}
+	pages := []page{}
+	for _, result := range results {
+		p := page{URL: result.URL, Posts: result.Posts}
+		if result.Err != nil {
+			p.Error = result.Err.Error()
+		}
+		pages = append(pages, p)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(pages)
+}
+
+// orDash - a missing field stands out in the table
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
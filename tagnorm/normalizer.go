@@ -0,0 +1,152 @@
+package tagnorm
+
+import (
+	"devread/helper"
+	"devread/repository"
+
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// refreshAfter - aliases are reloaded from the database when older than this,
+// so every process sees admin edits without restarting
+const refreshAfter = 5 * time.Minute
+
+// Normalizer - maps every spelling of a tag to one canonical slug
+type Normalizer struct {
+	tagRepo repository.TagRepo
+	logger  *zap.Logger
+
+	mu       sync.RWMutex
+	aliases  map[string]string
+	loadedAt time.Time
+}
+
+func NewNormalizer(tagRepo repository.TagRepo, logger *zap.Logger) *Normalizer {
+	return &Normalizer{
+		tagRepo: tagRepo,
+		logger:  logger,
+		aliases: map[string]string{},
+	}
+}
+
+// Reload - reads the alias table again
+func (n *Normalizer) Reload(ctx context.Context) error {
+	rows, err := n.tagRepo.SelectAliases(ctx)
+	if err != nil {
+		return err
+	}
+
+	aliases := make(map[string]string, len(rows))
+	for _, row := range rows {
+		aliases[helper.Slugify(row.Alias)] = helper.Slugify(row.Tag)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.aliases = aliases
+	n.loadedAt = time.Now()
+	return nil
+}
+
+// Canonical - slug of the tag, replaced by its alias target if there is one:
+// "Golang", "go-lang" -> "go"
+func (n *Normalizer) Canonical(tag string) string {
+	n.refresh()
+
+	slug := helper.Slugify(tag)
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if target, ok := n.aliases[slug]; ok {
+		return target
+	}
+	return slug
+}
+
+// CanonicalList - canonical form of every tag, without duplicates
+func (n *Normalizer) CanonicalList(tags []string) []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		canonical := n.Canonical(tag)
+		if canonical == "" || seen[canonical] {
+			continue
+		}
+		seen[canonical] = true
+		result = append(result, canonical)
+	}
+	return result
+}
+
+func (n *Normalizer) refresh() {
+	n.mu.RLock()
+	stale := time.Since(n.loadedAt) > refreshAfter
+	n.mu.RUnlock()
+	if !stale {
+		return
+	}
+
+	if err := n.Reload(context.Background()); err != nil {
+		n.logger.Error("Tải alias tag thất bại ", zap.Error(err))
+		// keep the old aliases and retry later
+		n.mu.Lock()
+		n.loadedAt = time.Now()
+		n.mu.Unlock()
+	}
+}
+
+// Backfill - rewrites the tags already stored to their canonical form,
+// returns how many tags were merged and how many posts were updated
+func (n *Normalizer) Backfill(ctx context.Context) (int, int64, error) {
+	if err := n.Reload(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	names, err := n.tagRepo.SelectNames(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	merged := 0
+	for _, name := range names {
+		canonical := n.Canonical(name)
+		if canonical == name || canonical == "" {
+			continue
+		}
+		if err := n.tagRepo.Merge(ctx, name, canonical); err != nil {
+			return merged, 0, err
+		}
+		n.logger.Sugar().Info("Gộp tag ", name, " -> ", canonical)
+		merged++
+	}
+
+	posts, err := n.tagRepo.SelectPostTags(ctx)
+	if err != nil {
+		return merged, 0, err
+	}
+	var updated int64
+	for _, post := range posts {
+		canonical := n.Canonical(primaryTag(post.Source, post.Tag))
+		if canonical == post.Tag {
+			continue
+		}
+		count, err := n.tagRepo.RenamePostTag(ctx, post.Source, post.Tag, canonical)
+		if err != nil {
+			return merged, updated, err
+		}
+		updated += count
+	}
+	return merged, updated, nil
+}
+
+// primaryTag - quan-cam used to store every hashtag in one space separated string, keep the first one
+func primaryTag(source, tag string) string {
+	fields := strings.Fields(tag)
+	if source == "quancam" && len(fields) > 0 {
+		return fields[0]
+	}
+	return tag
+}
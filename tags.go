@@ -0,0 +1,32 @@
+package main
+
+import (
+	"devread/repository/repo_impl"
+	"devread/tagnorm"
+
+	"context"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// tags - maintenance of stored tags, "backfill" rewrites them to their canonical form
+func tags(ctx context.Context, log *zap.Logger, args []string) int {
+	if len(args) != 1 || args[0] != "backfill" {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	sql := connectPostgres(log)
+	defer sql.Close()
+
+	tagNormalizer := tagnorm.NewNormalizer(repo_impl.NewTagRepo(sql), log)
+	merged, updated, err := tagNormalizer.Backfill(ctx)
+	fmt.Printf("Đã gộp %d tag, cập nhật %d bài viết\n", merged, updated)
+	if err != nil {
+		log.Error("Chuẩn hoá tag thất bại ", zap.Error(err))
+		return 1
+	}
+	return 0
+}